@@ -1,9 +1,25 @@
 package http_routing
 
+// AnyMethod stands in for the method of an Any leaf wherever a route needs a
+// method name, such as in a RouteDescription.
+const AnyMethod = "*"
+
 type Compiler[Endpoint any, Branch any, Out any] interface {
 	Root(missing Endpoint) func(branches ...Branch) Out
+	RootWithMethodNotAllowed(missing Endpoint, methodNotAllowed Endpoint) func(branches ...Branch) Out
 	Path(prefix string) func(branches ...Branch) Branch
 	Param(name string) func(branches ...Branch) Branch
+	ConstrainedParam(name string, constraint ParamConstraint) func(branches ...Branch) Branch
+	CatchAll(name string) func(branches ...Branch) Branch
+	Mount(prefix string) func(branches ...Branch) Branch
+	Named(name string) func(branches ...Branch) Branch
+	Host(pattern string) func(branches ...Branch) Branch
+	Where(predicate RequestPredicate) func(branches ...Branch) Branch
+	With(middleware ...Middleware[Endpoint]) func(branches ...Branch) Branch
+	Meta(key string, value interface{}) func(branches ...Branch) Branch
+	Method(name string, endpoint Endpoint) Branch
+	Methods(names []string, endpoint Endpoint) Branch
+	Any(endpoint Endpoint) Branch
 	Get(endpoint Endpoint) Branch
 	Post(endpoint Endpoint) Branch
 	Put(endpoint Endpoint) Branch
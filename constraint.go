@@ -0,0 +1,128 @@
+package http_routing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ParamConstraint interface {
+	Matches(value string) bool
+	String() string
+}
+
+type intConstraint struct{}
+
+func IntConstraint() ParamConstraint {
+	return intConstraint{}
+}
+
+func (constraint intConstraint) Matches(value string) bool {
+	digits := strings.TrimPrefix(value, "-")
+	if digits == "" {
+		return false
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (constraint intConstraint) String() string {
+	return "int"
+}
+
+type uuidConstraint struct{}
+
+func UuidConstraint() ParamConstraint {
+	return uuidConstraint{}
+}
+
+func isHexDigit(char byte) bool {
+	return (char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+func (constraint uuidConstraint) Matches(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if value[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(value[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (constraint uuidConstraint) String() string {
+	return "uuid"
+}
+
+type regexConstraint struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+func RegexConstraint(pattern string) ParamConstraint {
+	return regexConstraint{pattern, regexp.MustCompile("^(?:" + pattern + ")$")}
+}
+
+func (constraint regexConstraint) Matches(value string) bool {
+	return constraint.regex.MatchString(value)
+}
+
+func (constraint regexConstraint) String() string {
+	return "regex(" + constraint.pattern + ")"
+}
+
+type enumConstraint struct {
+	values []string
+}
+
+func EnumConstraint(values ...string) ParamConstraint {
+	return enumConstraint{values}
+}
+
+func (constraint enumConstraint) Matches(value string) bool {
+	for _, candidate := range constraint.values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (constraint enumConstraint) String() string {
+	return "enum(" + strings.Join(constraint.values, ",") + ")"
+}
+
+func parseParamConstraint(spec string) (ParamConstraint, error) {
+	switch {
+	case spec == "int":
+		return IntConstraint(), nil
+	case spec == "uuid":
+		return UuidConstraint(), nil
+	case strings.HasPrefix(spec, "regex(") && strings.HasSuffix(spec, ")"):
+		pattern := spec[len("regex(") : len(spec)-1]
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+		return RegexConstraint(pattern), nil
+	case strings.HasPrefix(spec, "enum(") && strings.HasSuffix(spec, ")"):
+		return EnumConstraint(strings.Split(spec[len("enum("):len(spec)-1], ",")...), nil
+	}
+	return nil, fmt.Errorf("unknown param constraint %q", spec)
+}
+
+func matchesConstraint(constraint ParamConstraint, value string) bool {
+	return constraint == nil || constraint.Matches(value)
+}
@@ -0,0 +1,62 @@
+package http_routing
+
+import (
+	"testing"
+)
+
+func TestParamConstraints(t *testing.T) {
+	var tests = []struct {
+		name       string
+		constraint ParamConstraint
+		value      string
+		expected   bool
+	}{
+		{name: "int", constraint: IntConstraint(), value: "1337", expected: true},
+		{name: "negative int", constraint: IntConstraint(), value: "-1", expected: true},
+		{name: "non int", constraint: IntConstraint(), value: "abc", expected: false},
+		{name: "empty int", constraint: IntConstraint(), value: "", expected: false},
+		{name: "sign only int", constraint: IntConstraint(), value: "-", expected: false},
+		{name: "uuid", constraint: UuidConstraint(), value: "123e4567-e89b-12d3-a456-426614174000", expected: true},
+		{name: "upper case uuid", constraint: UuidConstraint(), value: "123E4567-E89B-12D3-A456-426614174000", expected: true},
+		{name: "short uuid", constraint: UuidConstraint(), value: "123e4567-e89b-12d3-a456", expected: false},
+		{name: "misplaced dash uuid", constraint: UuidConstraint(), value: "123e4567e-89b-12d3-a456-426614174000", expected: false},
+		{name: "regex", constraint: RegexConstraint("[a-z]+"), value: "abc", expected: true},
+		{name: "regex is anchored", constraint: RegexConstraint("[a-z]+"), value: "abc1", expected: false},
+		{name: "regex alternation is anchored", constraint: RegexConstraint("a|b"), value: "ab", expected: false},
+		{name: "enum", constraint: EnumConstraint("draft", "published"), value: "draft", expected: true},
+		{name: "non enum", constraint: EnumConstraint("draft", "published"), value: "deleted", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.constraint.Matches(test.value); result != test.expected {
+				t.Errorf("got %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseParamConstraint(t *testing.T) {
+	var tests = []struct {
+		spec     string
+		expected string
+		err      bool
+	}{
+		{spec: "int", expected: "int"},
+		{spec: "uuid", expected: "uuid"},
+		{spec: "regex([a-z]+)", expected: "regex([a-z]+)"},
+		{spec: "enum(draft,published)", expected: "enum(draft,published)"},
+		{spec: "regex([a-z)", err: true},
+		{spec: "float", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			constraint, err := parseParamConstraint(test.spec)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if err == nil && constraint.String() != test.expected {
+				t.Errorf("got %q, want %q", constraint.String(), test.expected)
+			}
+		})
+	}
+}
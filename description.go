@@ -7,30 +7,105 @@ func NewDescriptionCompiler[Endpoint any]() Compiler[Endpoint, []RouteDescriptio
 }
 
 type Description[Endpoint any] struct {
-	Missing Endpoint
-	Routes  []RouteDescription[Endpoint]
+	Missing          Endpoint
+	MethodNotAllowed *Endpoint
+	Routes           []RouteDescription[Endpoint]
 }
 
 type RouteDescription[Endpoint any] struct {
-	Method   string
-	Path     string
-	Endpoint Endpoint
+	Method      string
+	Host        string
+	Path        string
+	Segments    []PathSegment
+	Endpoint    Endpoint
+	Constraints map[string]ParamConstraint
+	Predicates  []RequestPredicate
+	Middleware  []Middleware[Endpoint]
+	Meta        map[string]interface{}
+	Name        string
 }
 
-func (description RouteDescription[Endpoint]) prefixedWithPath(prefix string) RouteDescription[Endpoint] {
-	return RouteDescription[Endpoint]{
-		Method:   description.Method,
-		Path:     prefix + description.Path,
-		Endpoint: description.Endpoint,
+// prefixedWithSegment keeps Path as the rendering of Segments, so parsing
+// Path with ParsePath gives Segments back.
+func (description RouteDescription[Endpoint]) prefixedWithSegment(segment PathSegment) RouteDescription[Endpoint] {
+	if description.Host != "" && segment.Kind != StaticSegment && parseHostPattern(description.Host).capturesName(segment.Value) {
+		panic(hostParamCollision(description.Host, segment.Value))
 	}
+	description.Segments = prependPathSegment(segment, description.Segments)
+	description.Path = segment.String() + description.Path
+	return description
+}
+
+func (description RouteDescription[Endpoint]) prefixedWithPath(prefix string) RouteDescription[Endpoint] {
+	return description.prefixedWithSegment(PathSegment{StaticSegment, prefix, nil})
 }
 
 func (description RouteDescription[Endpoint]) prefixedWithParam(name string) RouteDescription[Endpoint] {
-	return RouteDescription[Endpoint]{
-		Method:   description.Method,
-		Path:     "/{" + name + description.Path + "}",
-		Endpoint: description.Endpoint,
+	return description.prefixedWithSegment(PathSegment{ParamSegment, name, nil})
+}
+
+func (description RouteDescription[Endpoint]) prefixedWithConstrainedParam(
+	name string,
+	constraint ParamConstraint,
+) RouteDescription[Endpoint] {
+	constraints := make(map[string]ParamConstraint, len(description.Constraints)+1)
+	for key, value := range description.Constraints {
+		constraints[key] = value
 	}
+	constraints[name] = constraint
+	description.Constraints = constraints
+	return description.prefixedWithSegment(PathSegment{ParamSegment, name, constraint})
+}
+
+func (description RouteDescription[Endpoint]) prefixedWithCatchAll(name string) RouteDescription[Endpoint] {
+	return description.prefixedWithSegment(PathSegment{CatchAllSegment, name, nil})
+}
+
+func (description RouteDescription[Endpoint]) named(name string) RouteDescription[Endpoint] {
+	if description.Name == "" {
+		description.Name = name
+	}
+	return description
+}
+
+func (description RouteDescription[Endpoint]) hosted(host hostPattern) RouteDescription[Endpoint] {
+	if description.Host != "" {
+		panic(nestedHost(host.pattern))
+	}
+	for _, segment := range description.Segments {
+		if segment.Kind != StaticSegment && host.capturesName(segment.Value) {
+			panic(hostParamCollision(host.pattern, segment.Value))
+		}
+	}
+	description.Host = host.pattern
+	constraints := host.constraints()
+	if len(constraints) == 0 {
+		return description
+	}
+	for key, value := range description.Constraints {
+		constraints[key] = value
+	}
+	description.Constraints = constraints
+	return description
+}
+
+func (description RouteDescription[Endpoint]) prefixedWithPredicate(predicate RequestPredicate) RouteDescription[Endpoint] {
+	predicates := make([]RequestPredicate, 0, len(description.Predicates)+1)
+	predicates = append(predicates, predicate)
+	description.Predicates = append(predicates, description.Predicates...)
+	return description
+}
+
+func (description RouteDescription[Endpoint]) prefixedWithMiddleware(
+	middleware []Middleware[Endpoint],
+) RouteDescription[Endpoint] {
+	description.Middleware = prependMiddleware(middleware, description.Middleware)
+	return description
+}
+
+func (description RouteDescription[Endpoint]) withMeta(key string, value interface{}) RouteDescription[Endpoint] {
+	description.Meta = withOuterMeta(description.Meta, key, value)
+	return description
 }
 
 func prefixBranches[Endpoint any](
@@ -49,6 +124,15 @@ func (describer DescriptionCompiler[Endpoint]) Root(
 	}
 }
 
+func (describer DescriptionCompiler[Endpoint]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(branches ...[]RouteDescription[Endpoint]) Description[Endpoint] {
+	return func(branches ...[]RouteDescription[Endpoint]) Description[Endpoint] {
+		return Description[Endpoint]{Missing: missing, MethodNotAllowed: &methodNotAllowed, Routes: flatten(branches)}
+	}
+}
+
 func (describer DescriptionCompiler[Endpoint]) Path(
 	prefix string,
 ) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
@@ -65,38 +149,126 @@ func (describer DescriptionCompiler[Endpoint]) Param(
 	})
 }
 
+func (describer DescriptionCompiler[Endpoint]) ConstrainedParam(
+	name string,
+	constraint ParamConstraint,
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.prefixedWithConstrainedParam(name, constraint)
+	})
+}
+
+func (describer DescriptionCompiler[Endpoint]) CatchAll(
+	name string,
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.prefixedWithCatchAll(name)
+	})
+}
+
+func (describer DescriptionCompiler[Endpoint]) Mount(
+	prefix string,
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return mountPrefix[[]RouteDescription[Endpoint]](describer, prefix)
+}
+
+func (describer DescriptionCompiler[Endpoint]) Named(
+	name string,
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.named(name)
+	})
+}
+
+func (describer DescriptionCompiler[Endpoint]) Host(
+	pattern string,
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	host := parseHostPattern(pattern)
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.hosted(host)
+	})
+}
+
+func (describer DescriptionCompiler[Endpoint]) Where(
+	predicate RequestPredicate,
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.prefixedWithPredicate(predicate)
+	})
+}
+
+func (describer DescriptionCompiler[Endpoint]) With(
+	middleware ...Middleware[Endpoint],
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.prefixedWithMiddleware(middleware)
+	})
+}
+
+func (describer DescriptionCompiler[Endpoint]) Meta(
+	key string,
+	value interface{},
+) func(branches ...[]RouteDescription[Endpoint]) []RouteDescription[Endpoint] {
+	return prefixBranches(func(description RouteDescription[Endpoint]) RouteDescription[Endpoint] {
+		return description.withMeta(key, value)
+	})
+}
+
+func describeMethod[Endpoint any](method string, endpoint Endpoint) []RouteDescription[Endpoint] {
+	return []RouteDescription[Endpoint]{{Method: method, Endpoint: endpoint}}
+}
+
+func (describer DescriptionCompiler[Endpoint]) Method(name string, endpoint Endpoint) []RouteDescription[Endpoint] {
+	return describeMethod(name, endpoint)
+}
+
+// Methods describes one route per method, in the order given.
+func (describer DescriptionCompiler[Endpoint]) Methods(names []string, endpoint Endpoint) []RouteDescription[Endpoint] {
+	routes := make([]RouteDescription[Endpoint], 0, len(names))
+	for _, name := range names {
+		routes = append(routes, describeMethod(name, endpoint)...)
+	}
+	return routes
+}
+
+// Any is described as a single route whose method is AnyMethod, since the
+// methods it answers can't be listed.
+func (describer DescriptionCompiler[Endpoint]) Any(endpoint Endpoint) []RouteDescription[Endpoint] {
+	return describeMethod(AnyMethod, endpoint)
+}
+
 func (describer DescriptionCompiler[Endpoint]) Get(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"GET", "", endpoint}}
+	return describer.Method("GET", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Post(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"POST", "", endpoint}}
+	return describer.Method("POST", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Put(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"PUT", "", endpoint}}
+	return describer.Method("PUT", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Delete(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"DELETE", "", endpoint}}
+	return describer.Method("DELETE", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Options(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"OPTIONS", "", endpoint}}
+	return describer.Method("OPTIONS", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Patch(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"PATCH", "", endpoint}}
+	return describer.Method("PATCH", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Head(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"HEAD", "", endpoint}}
+	return describer.Method("HEAD", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Connect(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"CONNECT", "", endpoint}}
+	return describer.Method("CONNECT", endpoint)
 }
 
 func (describer DescriptionCompiler[Endpoint]) Trace(endpoint Endpoint) []RouteDescription[Endpoint] {
-	return []RouteDescription[Endpoint]{{"TRACE", "", endpoint}}
+	return describer.Method("TRACE", endpoint)
 }
@@ -5,8 +5,28 @@ import (
 	"testing"
 )
 
+// withParsedSegments fills in each route's Segments by parsing its Path, so
+// expectations stay readable and also check that rendering round-trips.
+func withParsedSegments(t *testing.T, description Description[string]) Description[string] {
+	t.Helper()
+	routes := make([]RouteDescription[string], 0, len(description.Routes))
+	for _, route := range description.Routes {
+		if route.Path != "" {
+			segments, err := ParsePath(route.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			route.Segments = segments
+		}
+		routes = append(routes, route)
+	}
+	description.Routes = routes
+	return description
+}
+
 func TestDescriptionCompiler(t *testing.T) {
 	dsl := NewDescriptionCompiler[string]()
+	notAllowed := "notAllowed"
 	var tests = []struct {
 		name     string
 		result   Description[string]
@@ -40,6 +60,28 @@ func TestDescriptionCompiler(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "top level custom method",
+			result: dsl.Root("missing")(dsl.Method("PROPFIND", "PropfindAll")),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{Method: "PROPFIND", Endpoint: "PropfindAll"},
+				},
+			},
+		},
+		{
+			name:   "several methods and any method",
+			result: dsl.Root("missing")(dsl.Methods([]string{"GET", "HEAD"}, "FetchAll"), dsl.Any("Proxy")),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{Method: "GET", Endpoint: "FetchAll"},
+					{Method: "HEAD", Endpoint: "FetchAll"},
+					{Method: AnyMethod, Endpoint: "Proxy"},
+				},
+			},
+		},
 		{
 			name:   "top level put",
 			result: dsl.Root("missing")(dsl.Put("PutAll")),
@@ -178,12 +220,217 @@ func TestDescriptionCompiler(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "constrained params",
+			result: dsl.Root("missing")(dsl.Path("/users")(dsl.ConstrainedParam("user_id", IntConstraint())(
+				dsl.Path("/posts")(dsl.ConstrainedParam("state", EnumConstraint("draft", "published"))(
+					dsl.Get("FetchPostsByState"),
+				)),
+			))),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{
+						Method:   "GET",
+						Path:     "/users/{user_id:int}/posts/{state:enum(draft,published)}",
+						Endpoint: "FetchPostsByState",
+						Constraints: map[string]ParamConstraint{
+							"user_id": IntConstraint(),
+							"state":   EnumConstraint("draft", "published"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "named routes",
+			result: dsl.Root("missing")(dsl.Named("users")(dsl.Path("/users")(
+				dsl.Post("CreateUser"),
+				dsl.Param("user_id")(dsl.Named("users.fetch")(dsl.Get("FetchUserById"))),
+			))),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{Method: "POST", Path: "/users", Endpoint: "CreateUser", Name: "users"},
+					{Method: "GET", Path: "/users/{user_id}", Endpoint: "FetchUserById", Name: "users.fetch"},
+				},
+			},
+		},
+		{
+			name: "host routes",
+			result: dsl.Root("missing")(
+				dsl.Host("{tenant:enum(acme,globex)}.example.com")(dsl.Path("/users")(
+					dsl.Param("user_id")(dsl.Get("FetchUserById")),
+				)),
+				dsl.Path("/users")(dsl.Get("ListUsers")),
+			),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{
+						Method:      "GET",
+						Host:        "{tenant:enum(acme,globex)}.example.com",
+						Path:        "/users/{user_id}",
+						Endpoint:    "FetchUserById",
+						Constraints: map[string]ParamConstraint{"tenant": EnumConstraint("acme", "globex")},
+					},
+					{Method: "GET", Path: "/users", Endpoint: "ListUsers"},
+				},
+			},
+		},
+		{
+			name: "predicates",
+			result: dsl.Root("missing")(dsl.Path("/users")(
+				dsl.Where(AcceptPredicate("application/vnd.v2+json"))(
+					dsl.Where(QueryPredicate("beta", "1"))(dsl.Get("ListUsersBeta")),
+					dsl.Get("ListUsersV2"),
+				),
+				dsl.Get("ListUsers"),
+			)),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{
+						Method:     "GET",
+						Path:       "/users",
+						Endpoint:   "ListUsersBeta",
+						Predicates: []RequestPredicate{AcceptPredicate("application/vnd.v2+json"), QueryPredicate("beta", "1")},
+					},
+					{
+						Method:     "GET",
+						Path:       "/users",
+						Endpoint:   "ListUsersV2",
+						Predicates: []RequestPredicate{AcceptPredicate("application/vnd.v2+json")},
+					},
+					{Method: "GET", Path: "/users", Endpoint: "ListUsers"},
+				},
+			},
+		},
+		{
+			name: "meta",
+			result: dsl.Root("missing")(dsl.Meta("owner", "identity")(dsl.Path("/users")(
+				dsl.Meta("scope", "users:write")(dsl.Meta("owner", "accounts")(dsl.Post("CreateUser"))),
+				dsl.Get("ListUsers"),
+			))),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{
+						Method:   "POST",
+						Path:     "/users",
+						Endpoint: "CreateUser",
+						Meta:     map[string]interface{}{"owner": "accounts", "scope": "users:write"},
+					},
+					{
+						Method:   "GET",
+						Path:     "/users",
+						Endpoint: "ListUsers",
+						Meta:     map[string]interface{}{"owner": "identity"},
+					},
+				},
+			},
+		},
+		{
+			name: "mounted routes",
+			result: dsl.Root("missing")(
+				dsl.Mount("/orgs/{org_id}/billing/")(
+					dsl.Path("/invoices")(dsl.Param("invoice_id")(dsl.Get("FetchInvoice"))),
+				),
+				dsl.Mount("/")(dsl.Path("/health")(dsl.Get("Health"))),
+			),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{Method: "GET", Path: "/orgs/{org_id}/billing/invoices/{invoice_id}", Endpoint: "FetchInvoice"},
+					{Method: "GET", Path: "/health", Endpoint: "Health"},
+				},
+			},
+		},
+		{
+			name: "catch all",
+			result: dsl.Root("missing")(dsl.Path("/static")(dsl.CatchAll("file")(
+				dsl.Get("StaticFile"),
+			))),
+			expected: Description[string]{
+				Missing: "missing",
+				Routes: []RouteDescription[string]{
+					{Method: "GET", Path: "/static/{file...}", Endpoint: "StaticFile"},
+				},
+			},
+		},
+		{
+			name: "method not allowed",
+			result: dsl.RootWithMethodNotAllowed("missing", "notAllowed")(dsl.Path("/users")(
+				dsl.Post("CreateUser"),
+			)),
+			expected: Description[string]{
+				Missing:          "missing",
+				MethodNotAllowed: &notAllowed,
+				Routes: []RouteDescription[string]{
+					{Method: "POST", Path: "/users", Endpoint: "CreateUser"},
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			if !reflect.DeepEqual(test.result, test.expected) {
-				t.Errorf("got %+v, want %+v", test.result, test.expected)
+			expected := withParsedSegments(t, test.expected)
+			if !reflect.DeepEqual(test.result, expected) {
+				t.Errorf("got %+v, want %+v", test.result, expected)
 			}
 		})
 	}
 }
+
+func TestDescriptionCompilerWith(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("missing")(
+		dsl.With(wrappingMiddleware("Log"))(dsl.Path("/users")(
+			dsl.With(wrappingMiddleware("Auth"))(dsl.Post("CreateUser")),
+			dsl.Get("ListUsers"),
+		)),
+		dsl.Path("/")(dsl.Get("Index")),
+	)
+	expected := []string{"Log(Auth(CreateUser))", "Log(ListUsers)", "Index"}
+	result := make([]string, 0, len(description.Routes))
+	for _, route := range description.Routes {
+		result = append(result, Chain(route.Middleware, route.Endpoint))
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %v, want %v", result, expected)
+	}
+}
+
+func TestDescriptionCompilerSegments(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("missing")(
+		dsl.Path("/users")(dsl.ConstrainedParam("user_id", IntConstraint())(
+			dsl.Path("/posts")(dsl.Param("post_id")(dsl.Path(".json")(dsl.Get("FetchPostJson")))),
+		)),
+		dsl.Mount("/")(dsl.Path("/static")(dsl.Path("/")(dsl.CatchAll("file")(dsl.Get("StaticFile"))))),
+	)
+	expected := [][]PathSegment{
+		{
+			{StaticSegment, "/users", nil},
+			{ParamSegment, "user_id", IntConstraint()},
+			{StaticSegment, "/posts", nil},
+			{ParamSegment, "post_id", nil},
+			{StaticSegment, ".json", nil},
+		},
+		{
+			{StaticSegment, "/static/", nil},
+			{CatchAllSegment, "file", nil},
+		},
+	}
+	for i, route := range description.Routes {
+		if !reflect.DeepEqual(route.Segments, expected[i]) {
+			t.Errorf("route %d: got %v, want %v", i, route.Segments, expected[i])
+		}
+		if rendered := RenderPath(route.Segments); rendered != route.Path {
+			t.Errorf("route %d: rendered %q, want %q", i, rendered, route.Path)
+		}
+	}
+	if description.Routes[0].Path != "/users/{user_id:int}/posts/{post_id}.json" {
+		t.Errorf("got %q", description.Routes[0].Path)
+	}
+}
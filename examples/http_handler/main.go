@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"github.com/unexcitingcode/http-routing"
+	"net/http"
+)
+
+func MakeRoutes[Branch any, Out any](dsl http_routing.Compiler[http.Handler, Branch, Out]) Out {
+	return dsl.Root(http.NotFoundHandler())(
+		dsl.Path("/")(dsl.Get(http.HandlerFunc(IndexRender))),
+		dsl.Path("/users")(
+			dsl.Param("user_id")(
+				dsl.Get(http.HandlerFunc(ApiFetchUser)),
+			),
+		),
+	)
+}
+
+func IndexRender(writer http.ResponseWriter, request *http.Request) {
+	fmt.Fprintln(writer, "index")
+}
+
+func ApiFetchUser(writer http.ResponseWriter, request *http.Request) {
+	fmt.Fprintf(writer, "user %s\n", http_routing.Param(request, "user_id"))
+}
+
+func main() {
+	handler := MakeRoutes(http_routing.NewHttpHandlerCompiler())
+	if err := http.ListenAndServe(":8080", handler); err != nil {
+		panic(err)
+	}
+}
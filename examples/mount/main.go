@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"github.com/unexcitingcode/http-routing"
+)
+
+func MakeBillingRoutes[Branch any, Out any](dsl http_routing.Compiler[string, Branch, Out]) Branch {
+	return dsl.Path("/invoices")(
+		dsl.Get("ListInvoices"),
+		dsl.Param("invoice_id")(dsl.Get("FetchInvoice")),
+	)
+}
+
+func MakeRoutes[Branch any, Out any](dsl http_routing.Compiler[string, Branch, Out]) Out {
+	return dsl.Root("Missing")(
+		dsl.Path("/")(dsl.Get("IndexRender")),
+		dsl.Mount("/orgs/{org_id:int}/billing")(MakeBillingRoutes(dsl)),
+	)
+}
+
+func main() {
+	routes := MakeRoutes(http_routing.NewRequestLineCompiler[string]())
+	request := http_routing.RequestLine{Method: "GET", Path: "/orgs/42/billing/invoices/7"}
+	fmt.Printf("%+v\n", routes(request))
+	fmt.Printf("%+v\n", MakeRoutes(http_routing.NewDescriptionCompiler[string]()))
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"github.com/unexcitingcode/http-routing"
+)
+
+func MakeRoutes[Branch any, Out any](dsl http_routing.Compiler[string, Branch, Out]) Out {
+	return dsl.Root("Missing")(
+		dsl.Path("/")(dsl.Get("IndexRender")),
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+				dsl.Put("ApiUpdateUser"),
+				dsl.Delete("ApiDeleteUser"),
+			),
+		),
+	)
+}
+
+func main() {
+	router := MakeRoutes(http_routing.NewReverseRouterCompiler[string]())
+	url, err := router.URL("ApiFetchUser", map[string]string{"user_id": "1337"})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(url)
+}
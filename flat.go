@@ -1,204 +1,471 @@
 package http_routing
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 )
 
+// FlatRouteTranspiler hands branches to its compiler in a stable order: at each
+// path segment, method routes and the index behind a trailing slash come first,
+// then static segments, constrained params, params and finally catch-alls, each
+// group in declaration order.
 type FlatRouteTranspiler[Endpoint any, Branch any, Root any] struct {
 	compiler Compiler[Endpoint, Branch, Root]
+	options  func(allowed []string) Endpoint
 }
 
 func NewFlatRouteTranspiler[Endpoint any, Branch any, Root any](
 	compiler Compiler[Endpoint, Branch, Root],
 ) FlatRouteTranspiler[Endpoint, Branch, Root] {
-	return FlatRouteTranspiler[Endpoint, Branch, Root]{compiler}
+	return FlatRouteTranspiler[Endpoint, Branch, Root]{compiler, nil}
 }
 
-type httpMethod int64
+func NewFlatRouteTranspilerWithAutomaticMethods[Endpoint any, Branch any, Root any](
+	compiler Compiler[Endpoint, Branch, Root],
+	options func(allowed []string) Endpoint,
+) FlatRouteTranspiler[Endpoint, Branch, Root] {
+	return FlatRouteTranspiler[Endpoint, Branch, Root]{compiler, options}
+}
+
+type httpMethod string
 
 const (
-	Get httpMethod = iota
-	Post
-	Put
-	Delete
-	Options
-	Patch
-	Head
-	Connect
-	Trace
+	Get     httpMethod = "GET"
+	Post    httpMethod = "POST"
+	Put     httpMethod = "PUT"
+	Delete  httpMethod = "DELETE"
+	Options httpMethod = "OPTIONS"
+	Patch   httpMethod = "PATCH"
+	Head    httpMethod = "HEAD"
+	Connect httpMethod = "CONNECT"
+	Trace   httpMethod = "TRACE"
+)
+
+// valid accepts any upper-case token starting with a letter, so WebDAV and
+// in-house verbs can be routed while a lower-case "get" or a stray "123" is
+// still caught as a typo.
+func (method httpMethod) valid() bool {
+	if method == "" || method[0] < 'A' || method[0] > 'Z' {
+		return false
+	}
+	for _, c := range method {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func (method httpMethod) String() string {
+	return string(method)
+}
+
+var (
+	ErrUnknownMethod = errors.New("unknown method")
+	ErrMalformedPath = errors.New("malformed path")
+
+	ErrEmptyPath          = fmt.Errorf("%w: empty", ErrMalformedPath)
+	ErrRelativePath       = fmt.Errorf("%w: must start with /", ErrMalformedPath)
+	ErrEmptySegment       = fmt.Errorf("%w: empty segment", ErrMalformedPath)
+	ErrUnbalancedBraces   = fmt.Errorf("%w: unbalanced braces", ErrMalformedPath)
+	ErrEmptyParamName     = fmt.Errorf("%w: empty param name", ErrMalformedPath)
+	ErrDuplicateParamName = fmt.Errorf("%w: duplicate param name", ErrMalformedPath)
+	ErrMalformedSegment   = fmt.Errorf("%w: a segment holds one {name}, {name:constraint} or {name...}", ErrMalformedPath)
+	ErrCatchAllNotLast    = fmt.Errorf("%w: catch-all must be the last segment", ErrMalformedPath)
 )
 
 type FlatRoute[Endpoint any] struct {
 	method   httpMethod
-	path     []string
+	path     []PathSegment
 	endpoint Endpoint
+	name     string
+	source   string
 }
 
 func newFlatRoute[Endpoint any](method httpMethod, path string, endpoint Endpoint) FlatRoute[Endpoint] {
-	return FlatRoute[Endpoint]{method, strings.Split(path[1:], "/"), endpoint}
+	return FlatRoute[Endpoint]{method, nil, endpoint, "", path}
 }
 
-func (flatRoute FlatRoute[Endpoint]) shift() (string, FlatRoute[Endpoint]) {
+// flatPathPieces splits the statics ParsePath reads at every slash, so routes
+// sharing a prefix share the branches compiled for it.
+func flatPathPieces(path string) ([]PathSegment, error) {
+	segments, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	pieces := make([]PathSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.Kind != StaticSegment {
+			pieces = append(pieces, segment)
+			continue
+		}
+		for static := segment.Value; static != ""; {
+			end := strings.IndexByte(static[1:], '/') + 1
+			if end == 0 {
+				end = len(static)
+			}
+			pieces = append(pieces, PathSegment{StaticSegment, static[:end], nil})
+			static = static[end:]
+		}
+	}
+	return pieces, nil
+}
+
+// flatPathErrors adds what flat routes forbid on top of the ParsePath grammar,
+// and reports every problem in path order rather than the first, so a route
+// table can be fixed in one pass. A trailing slash is not an empty segment.
+func flatPathErrors(path string) []error {
+	if path == "" {
+		return []error{ErrEmptyPath}
+	}
+	segments, errs := scanPath(path, nil)
+	if !strings.HasPrefix(path, "/") {
+		errs = append(errs, pathError{0, ErrRelativePath})
+	}
+	names := make(map[string]bool, len(segments))
+	emptySegment := false
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment.Kind == StaticSegment {
+			empty := strings.Contains(segment.Value, "//") || (!last && strings.HasSuffix(segment.Value, "/"))
+			if empty && !emptySegment {
+				emptySegment = true
+				errs = append(errs, pathError{i, ErrEmptySegment})
+			}
+			if i > 0 && !strings.HasPrefix(segment.Value, "/") {
+				suffix, _, _ := strings.Cut(segment.Value, "/")
+				errs = append(errs, pathError{i, fmt.Errorf("%w, not %q", ErrMalformedSegment, segments[i-1].String()[1:]+suffix)})
+			}
+			continue
+		}
+		if segment.Kind == CatchAllSegment && !last {
+			errs = append(errs, pathError{i, fmt.Errorf("%w %q", ErrCatchAllNotLast, segment.String()[1:])})
+		}
+		if segment.Value != "" && names[segment.Value] {
+			errs = append(errs, pathError{i, fmt.Errorf("%w %q", ErrDuplicateParamName, segment.Value)})
+		}
+		names[segment.Value] = true
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].at < errs[j].at
+	})
+	out := make([]error, 0, len(errs))
+	for _, err := range errs {
+		out = append(out, err.err)
+	}
+	return out
+}
+
+type FlatRouteError struct {
+	Index  int
+	Method string
+	Path   string
+	Err    error
+}
+
+func (err FlatRouteError) Error() string {
+	return fmt.Sprintf("route %d %s %q: %v", err.Index, err.Method, err.Path, err.Err)
+}
+
+func (err FlatRouteError) Unwrap() error {
+	return err.Err
+}
+
+type FlatRouteErrors []FlatRouteError
+
+func (errs FlatRouteErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func validateFlatRoutes[Endpoint any](routes []FlatRoute[Endpoint]) FlatRouteErrors {
+	errs := FlatRouteErrors{}
+	for i, route := range routes {
+		if !route.method.valid() {
+			errs = append(errs, FlatRouteError{i, route.method.String(), route.source, fmt.Errorf("%w %q", ErrUnknownMethod, route.method)})
+		}
+		for _, err := range flatPathErrors(route.source) {
+			errs = append(errs, FlatRouteError{i, route.method.String(), route.source, err})
+		}
+	}
+	return errs
+}
+
+func (flatRoute FlatRoute[Endpoint]) shift() (PathSegment, FlatRoute[Endpoint]) {
 	if len(flatRoute.path) == 0 {
-		return "", flatRoute
+		return PathSegment{}, flatRoute
 	}
 	x, xs := flatRoute.path[0], flatRoute.path[1:]
-	return x, FlatRoute[Endpoint]{flatRoute.method, xs, flatRoute.endpoint}
+	flatRoute.path = xs
+	return x, flatRoute
 
 }
 
-func groupByAndShift[Endpoint any](routes []FlatRoute[Endpoint]) map[string][]FlatRoute[Endpoint] {
-	groups := make(map[string][]FlatRoute[Endpoint])
+type flatGroup[Endpoint any] struct {
+	segment PathSegment
+	routes  []FlatRoute[Endpoint]
+}
+
+// isFlatEnd is the segment shift returns once a route's path is used up.
+func isFlatEnd(segment PathSegment) bool {
+	return segment.Kind == StaticSegment && segment.Value == ""
+}
+
+func flatSegmentPrecedence(segment PathSegment) int {
+	switch {
+	case isFlatEnd(segment), segment.Kind == StaticSegment && segment.Value == "/":
+		return 0
+	case segment.Kind == CatchAllSegment:
+		return 4
+	case segment.Kind == ParamSegment && segment.Constraint != nil:
+		return 2
+	case segment.Kind == ParamSegment:
+		return 3
+	}
+	return 1
+}
+
+func groupByAndShift[Endpoint any](routes []FlatRoute[Endpoint]) []flatGroup[Endpoint] {
+	groups := make([]flatGroup[Endpoint], 0, len(routes))
+	indexes := make(map[string]int)
 	for _, route := range routes {
 		head, remaining := route.shift()
-		groups[head] = append(groups[head], remaining)
+		key := head.String()
+		index, ok := indexes[key]
+		if !ok {
+			index = len(groups)
+			indexes[key] = index
+			groups = append(groups, flatGroup[Endpoint]{segment: head})
+		}
+		groups[index].routes = append(groups[index].routes, remaining)
 	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return flatSegmentPrecedence(groups[i].segment) < flatSegmentPrecedence(groups[j].segment)
+	})
 	return groups
 }
 
-func compileSegment[Endpoint any, Branch any, Root any](
+func withAutomaticRoutes[Endpoint any](
+	routes []FlatRoute[Endpoint],
+	options func(allowed []string) Endpoint,
+) []FlatRoute[Endpoint] {
+	order := make([]string, 0, len(routes))
+	byPath := make(map[string][]FlatRoute[Endpoint])
+	for _, route := range routes {
+		key := RenderPath(route.path)
+		if _, ok := byPath[key]; !ok {
+			order = append(order, key)
+		}
+		byPath[key] = append(byPath[key], route)
+	}
+	out := make([]FlatRoute[Endpoint], 0, len(routes)+2*len(order))
+	out = append(out, routes...)
+	for _, key := range order {
+		declared := byPath[key]
+		path, source := declared[0].path, declared[0].source
+		allowed := make([]string, 0, len(declared)+2)
+		endpoints := make(map[httpMethod]Endpoint, len(declared))
+		for _, route := range declared {
+			if _, ok := endpoints[route.method]; !ok {
+				endpoints[route.method] = route.endpoint
+				allowed = append(allowed, route.method.String())
+			}
+		}
+		get, hasGet := endpoints[Get]
+		if _, hasHead := endpoints[Head]; hasGet && !hasHead {
+			allowed = append(allowed, Head.String())
+			out = append(out, FlatRoute[Endpoint]{Head, path, get, "", source})
+		}
+		if _, hasOptions := endpoints[Options]; !hasOptions {
+			allowed = append(allowed, Options.String())
+			out = append(out, FlatRoute[Endpoint]{Options, path, options(allowed), "", source})
+		}
+	}
+	return out
+}
+
+func compileGroups[Endpoint any, Branch any, Root any](
 	compiler Compiler[Endpoint, Branch, Root],
-	segment string,
 	routes []FlatRoute[Endpoint],
-) Branch {
+) []Branch {
 	groups := groupByAndShift(routes)
-	branches := make([]Branch, 0, len(groups)+len(groups[""]))
-	for segment, children := range groups {
-		if segment == "" {
-			for _, child := range children {
-				compiled := compileHttpMethod(compiler, child.method, child.endpoint)
+	branches := make([]Branch, 0, len(routes))
+	for _, group := range groups {
+		if isFlatEnd(group.segment) {
+			for _, child := range group.routes {
+				compiled := compileFlatLeaf(compiler, child)
 				branches = append(branches, compiled)
 			}
 		} else {
-			compiled := compileSegment(compiler, segment, children)
+			compiled := compileSegment(compiler, group.segment, group.routes)
 			branches = append(branches, compiled)
 		}
 	}
-	if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
-		return compiler.Param(segment[1 : len(segment)-1])(branches...)
-	} else {
-		return compiler.Path("/" + segment)(branches...)
-	}
+	return branches
 }
 
-func compileIndexes[Endpoint any, Branch any, Root any](
+func compileSegment[Endpoint any, Branch any, Root any](
 	compiler Compiler[Endpoint, Branch, Root],
+	segment PathSegment,
 	routes []FlatRoute[Endpoint],
 ) Branch {
-	indexes := make([]Branch, 0, len(routes))
-	for _, child := range routes {
-		index := compileHttpMethod(compiler, child.method, child.endpoint)
-		indexes = append(indexes, index)
-	}
-	return compiler.Path("/")(indexes...)
+	branches := compileGroups(compiler, routes)
+	return segmentCombinator[Branch](compiler, segment.radix())(branches...)
 }
 
-func compileHttpMethod[Endpoint any, Branch any, Root any](
+func compileFlatLeaf[Endpoint any, Branch any, Root any](
 	compiler Compiler[Endpoint, Branch, Root],
-	method httpMethod,
-	endpoint Endpoint,
+	route FlatRoute[Endpoint],
 ) Branch {
-	switch method {
-	case Get:
-		return compiler.Get(endpoint)
-	case Post:
-		return compiler.Post(endpoint)
-	case Put:
-		return compiler.Put(endpoint)
-	case Delete:
-		return compiler.Delete(endpoint)
-	case Options:
-		return compiler.Options(endpoint)
-	case Patch:
-		return compiler.Patch(endpoint)
-	case Head:
-		return compiler.Head(endpoint)
-	case Connect:
-		return compiler.Connect(endpoint)
-	case Trace:
-		return compiler.Trace(endpoint)
-	}
-	panic("invalid http method")
+	leaf := compiler.Method(string(route.method), route.endpoint)
+	if route.name == "" {
+		return leaf
+	}
+	return compiler.Named(route.name)(leaf)
 }
 
-func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Root(
-	missing Endpoint,
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) compileRoot(
+	root func(branches ...Branch) Root,
 ) func(routes ...FlatRoute[Endpoint]) Root {
 	return func(routes ...FlatRoute[Endpoint]) Root {
-		groups := groupByAndShift(routes)
-		branches := make([]Branch, 0, len(groups))
-		for segment, children := range groups {
-			if segment == "" {
-				compiled := compileIndexes(transpiler.compiler, children)
-				branches = append(branches, compiled)
-			} else {
-				compiled := compileSegment(transpiler.compiler, segment, children)
-				branches = append(branches, compiled)
+		parsed := make([]FlatRoute[Endpoint], 0, len(routes))
+		for _, route := range routes {
+			pieces, err := flatPathPieces(route.source)
+			if err != nil {
+				panic(err)
 			}
+			route.path = pieces
+			parsed = append(parsed, route)
+		}
+		if transpiler.options != nil {
+			parsed = withAutomaticRoutes(parsed, transpiler.options)
+		}
+		return root(compileGroups(transpiler.compiler, parsed)...)
+	}
+}
+
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Root(
+	missing Endpoint,
+) func(routes ...FlatRoute[Endpoint]) Root {
+	return transpiler.compileRoot(transpiler.compiler.Root(missing))
+}
+
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(routes ...FlatRoute[Endpoint]) Root {
+	return transpiler.compileRoot(transpiler.compiler.RootWithMethodNotAllowed(missing, methodNotAllowed))
+}
+
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) compileCheckedRoot(
+	root func(branches ...Branch) Root,
+) func(routes ...FlatRoute[Endpoint]) (Root, error) {
+	compile := transpiler.compileRoot(root)
+	return func(routes ...FlatRoute[Endpoint]) (Root, error) {
+		if errs := validateFlatRoutes(routes); len(errs) > 0 {
+			var out Root
+			return out, errs
 		}
-		return transpiler.compiler.Root(missing)(branches...)
+		return compile(routes...), nil
 	}
 }
 
+// CheckedRoot validates every route before compiling any of them and returns
+// all problems found as FlatRouteErrors, where Root would panic or silently
+// build a router that can't match what was written.
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) CheckedRoot(
+	missing Endpoint,
+) func(routes ...FlatRoute[Endpoint]) (Root, error) {
+	return transpiler.compileCheckedRoot(transpiler.compiler.Root(missing))
+}
+
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) CheckedRootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(routes ...FlatRoute[Endpoint]) (Root, error) {
+	return transpiler.compileCheckedRoot(transpiler.compiler.RootWithMethodNotAllowed(missing, methodNotAllowed))
+}
+
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Named(
+	name string,
+	route FlatRoute[Endpoint],
+) FlatRoute[Endpoint] {
+	route.name = name
+	return route
+}
+
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Handle(
+	method string,
+	path string,
+	endpoint Endpoint,
+) FlatRoute[Endpoint] {
+	return newFlatRoute(httpMethod(method), path, endpoint)
+}
+
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Get(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Get, path, endpoint)
+	return transpiler.Handle("GET", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Post(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Post, path, endpoint)
+	return transpiler.Handle("POST", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Put(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Put, path, endpoint)
+	return transpiler.Handle("PUT", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Delete(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Delete, path, endpoint)
+	return transpiler.Handle("DELETE", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Options(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Options, path, endpoint)
+	return transpiler.Handle("OPTIONS", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Patch(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Patch, path, endpoint)
+	return transpiler.Handle("PATCH", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Head(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Head, path, endpoint)
+	return transpiler.Handle("HEAD", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Connect(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Connect, path, endpoint)
+	return transpiler.Handle("CONNECT", path, endpoint)
 }
 
 func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) Trace(
 	path string,
 	endpoint Endpoint,
 ) FlatRoute[Endpoint] {
-	return newFlatRoute(Trace, path, endpoint)
+	return transpiler.Handle("TRACE", path, endpoint)
 }
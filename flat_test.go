@@ -1,6 +1,7 @@
 package http_routing
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -27,6 +28,9 @@ func TestFlatRouteTranspilerWithRequestLineCompiler(t *testing.T) {
 		dsl.Get("/users/{user_id}", "ApiFetchUser"),
 		dsl.Put("/users/{user_id}", "ApiUpdateUser"),
 		dsl.Delete("/users/{user_id}", "ApiDeleteUser"),
+		dsl.Get("/static/{file...}", "StaticFile"),
+		dsl.Get("/posts/{post_id:int}", "ApiFetchPost"),
+		dsl.Put("/posts/{post_id:int}", "ApiUpdatePost"),
 	)
 	var tests = []struct {
 		name     string
@@ -137,6 +141,46 @@ func TestFlatRouteTranspilerWithRequestLineCompiler(t *testing.T) {
 				Params:   map[string]string{},
 			},
 		},
+		{
+			name:    "match catch all",
+			request: RequestLine{Method: "GET", Path: "/static/css/site.css"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticFile",
+				Params:   map[string]string{"file": "css/site.css"},
+			},
+		},
+		{
+			name:    "match empty catch all",
+			request: RequestLine{Method: "GET", Path: "/static/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticFile",
+				Params:   map[string]string{"file": ""},
+			},
+		},
+		{
+			name:    "match missing route via catch all prefix",
+			request: RequestLine{Method: "GET", Path: "/static"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match constrained param",
+			request: RequestLine{Method: "GET", Path: "/posts/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPost",
+				Params:   map[string]string{"post_id": "1337"},
+			},
+		},
+		{
+			name:    "match missing route via rejected constrained param",
+			request: RequestLine{Method: "GET", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
 		{
 			name:    "rest resource create",
 			request: RequestLine{Method: "POST", Path: "/users"},
@@ -179,3 +223,397 @@ func TestFlatRouteTranspilerWithRequestLineCompiler(t *testing.T) {
 		})
 	}
 }
+
+func TestFlatRouteTranspilerMethodNotAllowed(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Post("/users", "ApiCreateUser"),
+		dsl.Get("/users/{user_id}", "ApiFetchUser"),
+	)
+	result := routes(RequestLine{Method: "GET", Path: "/users"})
+	expected := RequestLineMatch[string]{
+		Endpoint: "NotAllowed",
+		Params:   map[string]string{},
+		Allowed:  []string{"POST"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestFlatRouteTranspilerWithAutomaticMethods(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspilerWithAutomaticMethods(compiler, describingOptions)
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Get("/", "IndexRender"),
+		dsl.Post("/users", "ApiCreateUser"),
+		dsl.Get("/users/{user_id}", "ApiFetchUser"),
+		dsl.Put("/users/{user_id}", "ApiUpdateUser"),
+		dsl.Get("/explicit", "ExplicitGet"),
+		dsl.Head("/explicit", "ExplicitHead"),
+		dsl.Options("/explicit", "ExplicitOptions"),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "head answered by get",
+			request: RequestLine{Method: "HEAD", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchUser",
+				Params:   map[string]string{"user_id": "1337"},
+			},
+		},
+		{
+			name:    "head answered by index get",
+			request: RequestLine{Method: "HEAD", Path: "/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "IndexRender",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "synthesized options",
+			request: RequestLine{Method: "OPTIONS", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Options GET,PUT,HEAD,OPTIONS",
+				Params:   map[string]string{"user_id": "1337"},
+			},
+		},
+		{
+			name:    "synthesized options without get",
+			request: RequestLine{Method: "OPTIONS", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Options POST,OPTIONS",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "explicit head and options",
+			request: RequestLine{Method: "HEAD", Path: "/explicit"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ExplicitHead",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "method not allowed",
+			request: RequestLine{Method: "DELETE", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"POST", "OPTIONS"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestFlatRouteTranspilerOrdering(t *testing.T) {
+	compiler := NewDescriptionCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	for i := 0; i < 20; i++ {
+		result := dsl.Root("Missing")(
+			dsl.Get("/static/{file...}", "StaticFile"),
+			dsl.Get("/users/{user_id}", "ApiFetchUser"),
+			dsl.Get("/users/{user_id:int}/posts", "ApiFetchUserPosts"),
+			dsl.Get("/users/me", "ApiFetchSelf"),
+			dsl.Post("/users", "ApiCreateUser"),
+			dsl.Get("/users", "ApiListUsers"),
+			dsl.Get("/", "IndexRender"),
+			dsl.Get("/static/robots.txt", "StaticRobots"),
+		)
+		expected := Description[string]{
+			Missing: "Missing",
+			Routes: []RouteDescription[string]{
+				{Method: "GET", Path: "/", Endpoint: "IndexRender"},
+				{Method: "GET", Path: "/static/robots.txt", Endpoint: "StaticRobots"},
+				{Method: "GET", Path: "/static/{file...}", Endpoint: "StaticFile"},
+				{Method: "POST", Path: "/users", Endpoint: "ApiCreateUser"},
+				{Method: "GET", Path: "/users", Endpoint: "ApiListUsers"},
+				{Method: "GET", Path: "/users/me", Endpoint: "ApiFetchSelf"},
+				{
+					Method:      "GET",
+					Path:        "/users/{user_id:int}/posts",
+					Endpoint:    "ApiFetchUserPosts",
+					Constraints: map[string]ParamConstraint{"user_id": IntConstraint()},
+				},
+				{Method: "GET", Path: "/users/{user_id}", Endpoint: "ApiFetchUser"},
+			},
+		}
+		expected = withParsedSegments(t, expected)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	}
+}
+
+func TestFlatRouteTranspilerRenderPath(t *testing.T) {
+	compiler := NewDescriptionCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	paths := []string{
+		"/",
+		"/users",
+		"/users/",
+		"/users/me",
+		"/users/{user_id:int}/posts",
+		"/codes/{code:regex([a-z]{3})}",
+		"/refs/{ref:regex(heads/[a-z]+)}/log",
+		"/static/{file...}",
+	}
+	routes := make([]FlatRoute[string], 0, len(paths))
+	for _, path := range paths {
+		routes = append(routes, dsl.Get(path, path))
+	}
+	result, err := dsl.CheckedRoot("Missing")(routes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Routes) != len(paths) {
+		t.Fatalf("got %d routes, want %d", len(result.Routes), len(paths))
+	}
+	for _, route := range result.Routes {
+		if route.Path != route.Endpoint {
+			t.Errorf("got path %q, want %q", route.Path, route.Endpoint)
+		}
+		if rendered := RenderPath(route.Segments); rendered != route.Endpoint {
+			t.Errorf("rendered %q, want %q", rendered, route.Endpoint)
+		}
+	}
+}
+
+func TestFlatRouteTranspilerStaticPrecedence(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	routes := dsl.Root("Missing")(
+		dsl.Get("/users/{user_id}", "ApiFetchUser"),
+		dsl.Get("/users/me", "ApiFetchSelf"),
+	)
+	result := routes(RequestLine{Method: "GET", Path: "/users/me"})
+	expected := RequestLineMatch[string]{Endpoint: "ApiFetchSelf", Params: map[string]string{}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestFlatRouteTranspilerConstrainedParamPrecedence(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	routes := dsl.Root("Missing")(
+		dsl.Get("/posts/{slug}", "ApiFetchPostBySlug"),
+		dsl.Get("/posts/{post_id:int}", "ApiFetchPost"),
+		dsl.Put("/posts/{post_id:int}", "ApiUpdatePost"),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "constrained param before param",
+			request: RequestLine{Method: "GET", Path: "/posts/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPost",
+				Params:   map[string]string{"post_id": "1337"},
+			},
+		},
+		{
+			name:    "constrained param falls through",
+			request: RequestLine{Method: "GET", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPostBySlug",
+				Params:   map[string]string{"slug": "latest"},
+			},
+		},
+		{
+			name:    "allowed methods via constrained param",
+			request: RequestLine{Method: "PUT", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := routes(test.request); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestFlatRouteTranspilerNamedRoutes(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	routes := dsl.Root("Missing")(
+		dsl.Named("users.fetch", dsl.Get("/users/{user_id}", "ApiFetchUser")),
+		dsl.Put("/users/{user_id}", "ApiUpdateUser"),
+	)
+	result := routes(RequestLine{Method: "GET", Path: "/users/1337"})
+	expected := RequestLineMatch[string]{
+		Endpoint: "ApiFetchUser",
+		Params:   map[string]string{"user_id": "1337"},
+		Name:     "users.fetch",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestFlatRouteTranspilerCheckedRoot(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	routes, err := dsl.CheckedRoot("Missing")(
+		dsl.Get("/users/{user_id:int}", "ApiFetchUser"),
+		dsl.Get("/users/", "ApiListUsers"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := routes(RequestLine{Method: "GET", Path: "/users/1337"})
+	if result.Endpoint != "ApiFetchUser" || result.Params["user_id"] != "1337" {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestFlatRouteTranspilerCheckedRootErrors(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspiler(compiler)
+	_, err := dsl.CheckedRootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Get("/users", "ApiListUsers"),
+		dsl.Get("", "Empty"),
+		dsl.Get("users", "Relative"),
+		dsl.Get("/users//{user_id}", "DoubleSlash"),
+		dsl.Get("/users/{user_id", "Unbalanced"),
+		dsl.Get("/users/{}/{:int}", "EmptyNames"),
+		dsl.Get("/orgs/{id}/users/{id}", "Duplicate"),
+		dsl.Get("/a/{x}{y}", "TwoParams"),
+		dsl.Get("/a/{x...}/b", "CatchAllNotLast"),
+		dsl.Handle("get", "/users", "Unknown"),
+	)
+	errs, ok := err.(FlatRouteErrors)
+	if !ok {
+		t.Fatalf("got %T %v, want FlatRouteErrors", err, err)
+	}
+	expected := []error{
+		ErrEmptyPath,
+		ErrRelativePath,
+		ErrEmptySegment,
+		ErrUnbalancedBraces,
+		ErrEmptyParamName,
+		ErrEmptyParamName,
+		ErrDuplicateParamName,
+		ErrMalformedSegment,
+		ErrCatchAllNotLast,
+		ErrUnknownMethod,
+	}
+	indexes := []int{1, 2, 3, 4, 5, 5, 6, 7, 8, 9}
+	if len(errs) != len(expected) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(expected), err)
+	}
+	for i, err := range errs {
+		if err.Index != indexes[i] || !errors.Is(err, expected[i]) {
+			t.Errorf("error %d: got %v, want route %d %v", i, err, indexes[i], expected[i])
+		}
+		if !errors.Is(err, ErrMalformedPath) && !errors.Is(err, ErrUnknownMethod) {
+			t.Errorf("error %d: %v is neither a malformed path nor an unknown method", i, err)
+		}
+	}
+	message := `route 6 GET "/orgs/{id}/users/{id}": malformed path: duplicate param name "id"`
+	if errs[6].Error() != message {
+		t.Errorf("got %q, want %q", errs[6].Error(), message)
+	}
+}
+
+func TestFlatPathErrors(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected []error
+	}{
+		{path: "/users/{}/{:int}", expected: []error{ErrEmptyParamName, ErrEmptyParamName}},
+		{path: "/a//{}", expected: []error{ErrEmptySegment, ErrEmptyParamName}},
+		{path: "users/{}", expected: []error{ErrRelativePath, ErrEmptyParamName}},
+		{path: "/a/{x}{y}/{x}", expected: []error{ErrMalformedSegment, ErrDuplicateParamName}},
+		{path: "/a/{x:float}/{y...}/b", expected: []error{ErrMalformedPath, ErrCatchAllNotLast}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			errs := flatPathErrors(test.path)
+			if len(errs) != len(test.expected) {
+				t.Fatalf("got %v, want %v", errs, test.expected)
+			}
+			for i, err := range errs {
+				if !errors.Is(err, test.expected[i]) {
+					t.Errorf("error %d: got %v, want %v", i, err, test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFlatRouteTranspilerCheckedRootMethods(t *testing.T) {
+	dsl := NewFlatRouteTranspiler(NewRequestLineCompiler[string]())
+	var tests = []struct {
+		method string
+		valid  bool
+	}{
+		{method: "GET", valid: true},
+		{method: "PROPFIND", valid: true},
+		{method: "VERSION-CONTROL", valid: true},
+		{method: "M_SEARCH2", valid: true},
+		{method: "", valid: false},
+		{method: "get", valid: false},
+		{method: "Get", valid: false},
+		{method: "123", valid: false},
+		{method: "2GET", valid: false},
+		{method: "-", valid: false},
+		{method: "_", valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			_, err := dsl.CheckedRoot("Missing")(dsl.Handle(test.method, "/", "Index"))
+			if test.valid && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if errs, ok := err.(FlatRouteErrors); !test.valid && (!ok || !errors.Is(errs[0], ErrUnknownMethod)) {
+				t.Errorf("got %v, want %v", err, ErrUnknownMethod)
+			}
+		})
+	}
+}
+
+func TestFlatRouteTranspilerHandle(t *testing.T) {
+	compiler := NewRequestLineCompiler[string]()
+	dsl := NewFlatRouteTranspilerWithAutomaticMethods(compiler, describingOptions)
+	routes, err := dsl.CheckedRootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Handle("PROPFIND", "/files/{path...}", "DavListFiles"),
+		dsl.Handle("GET", "/files/{path...}", "FetchFile"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := routes(RequestLine{Method: "PROPFIND", Path: "/files/docs/a.txt"})
+	expected := RequestLineMatch[string]{
+		Endpoint: "DavListFiles",
+		Params:   map[string]string{"path": "docs/a.txt"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+	result = routes(RequestLine{Method: "OPTIONS", Path: "/files/docs/a.txt"})
+	if result.Endpoint != "Options PROPFIND,GET,HEAD,OPTIONS" {
+		t.Errorf("got %+v", result)
+	}
+}
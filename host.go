@@ -0,0 +1,124 @@
+package http_routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+type hostLabel struct {
+	capture    bool
+	value      string
+	constraint ParamConstraint
+}
+
+type hostPattern struct {
+	pattern string
+	labels  []hostLabel
+}
+
+func parseHostPattern(pattern string) hostPattern {
+	host, err := compileHostPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return host
+}
+
+func compileHostPattern(pattern string) (hostPattern, error) {
+	pieces := strings.Split(strings.TrimSuffix(pattern, "."), ".")
+	labels := make([]hostLabel, 0, len(pieces))
+	names := make(map[string]bool, len(pieces))
+	for _, piece := range pieces {
+		if !strings.HasPrefix(piece, "{") || !strings.HasSuffix(piece, "}") {
+			labels = append(labels, hostLabel{false, piece, nil})
+			continue
+		}
+		name, spec, constrained := strings.Cut(piece[1:len(piece)-1], ":")
+		if names[name] {
+			return hostPattern{}, fmt.Errorf("host %q captures %q twice", pattern, name)
+		}
+		names[name] = true
+		if !constrained {
+			labels = append(labels, hostLabel{true, name, nil})
+			continue
+		}
+		constraint, err := parseParamConstraint(spec)
+		if err != nil {
+			return hostPattern{}, fmt.Errorf("host %q: %w", pattern, err)
+		}
+		labels = append(labels, hostLabel{true, name, constraint})
+	}
+	return hostPattern{pattern, labels}, nil
+}
+
+// nestedHost is the panic value for a Host wrapping routes that already have
+// one. Whether both hosts or only the innermost should apply has no obvious
+// answer, so every compiler rejects nesting.
+func nestedHost(outer string) error {
+	return fmt.Errorf("host %q cannot wrap routes that already have a host", outer)
+}
+
+// hostParamCollision is the panic value for a host capture named like a path
+// param of the same route, since only one of the two values could be matched.
+func hostParamCollision(pattern string, name string) error {
+	return fmt.Errorf("host %q captures %q, which the path also uses", pattern, name)
+}
+
+func (pattern hostPattern) capturesName(name string) bool {
+	for _, label := range pattern.labels {
+		if label.capture && label.value == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (pattern hostPattern) captures() int {
+	count := 0
+	for _, label := range pattern.labels {
+		if label.capture {
+			count++
+		}
+	}
+	return count
+}
+
+func (pattern hostPattern) constraints() map[string]ParamConstraint {
+	constraints := map[string]ParamConstraint{}
+	for _, label := range pattern.labels {
+		if label.capture && label.constraint != nil {
+			constraints[label.value] = label.constraint
+		}
+	}
+	return constraints
+}
+
+func hostname(host string) string {
+	if colon := strings.LastIndexByte(host, ':'); colon > strings.LastIndexByte(host, ']') {
+		host = host[:colon]
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+func (pattern hostPattern) match(host string, params RequestLineParams) (RequestLineParams, bool) {
+	length := len(params)
+	remaining := hostname(host)
+	for i, label := range pattern.labels {
+		value, rest, found := strings.Cut(remaining, ".")
+		if found != (i < len(pattern.labels)-1) {
+			return params[:length], false
+		}
+		remaining = rest
+		if !label.capture {
+			if !strings.EqualFold(label.value, value) {
+				return params[:length], false
+			}
+			continue
+		}
+		if value == "" || !matchesConstraint(label.constraint, value) {
+			return params[:length], false
+		}
+		params = append(params, RequestLineParam{label.value, value})
+	}
+	return params, true
+}
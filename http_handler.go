@@ -0,0 +1,100 @@
+package http_routing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type HttpHandlerCompiler struct {
+	RequestLineCompiler[http.Handler]
+}
+
+func NewHttpHandlerCompiler() Compiler[http.Handler, RequestLineBranch[http.Handler], http.Handler] {
+	return HttpHandlerCompiler{RequestLineCompiler[http.Handler]{}}
+}
+
+func NewHttpHandlerCompilerWithAutomaticMethods() Compiler[
+	http.Handler,
+	RequestLineBranch[http.Handler],
+	http.Handler,
+] {
+	return HttpHandlerCompiler{RequestLineCompiler[http.Handler]{automaticOptionsHandler}}
+}
+
+func automaticOptionsHandler(allowed []string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+}
+
+type routeContextKey struct{}
+
+type routeContext struct {
+	params map[string]string
+	name   string
+	meta   map[string]interface{}
+}
+
+func withRoute(request *http.Request, match RequestLineMatch[http.Handler]) *http.Request {
+	ctx := context.WithValue(request.Context(), routeContextKey{}, routeContext{match.Params, match.Name, match.Meta})
+	return request.WithContext(ctx)
+}
+
+func Params(request *http.Request) map[string]string {
+	route, ok := request.Context().Value(routeContextKey{}).(routeContext)
+	if !ok {
+		return map[string]string{}
+	}
+	return route.params
+}
+
+func Param(request *http.Request, name string) string {
+	return Params(request)[name]
+}
+
+func RouteName(request *http.Request) string {
+	route, _ := request.Context().Value(routeContextKey{}).(routeContext)
+	return route.name
+}
+
+func RouteMeta(request *http.Request) map[string]interface{} {
+	route, ok := request.Context().Value(routeContextKey{}).(routeContext)
+	if !ok || route.meta == nil {
+		return map[string]interface{}{}
+	}
+	return route.meta
+}
+
+func Meta(request *http.Request, key string) interface{} {
+	return RouteMeta(request)[key]
+}
+
+func (compiler HttpHandlerCompiler) Root(
+	missing http.Handler,
+) func(branches ...RequestLineBranch[http.Handler]) http.Handler {
+	return compiler.RootWithMethodNotAllowed(missing, missing)
+}
+
+func (compiler HttpHandlerCompiler) RootWithMethodNotAllowed(
+	missing http.Handler,
+	methodNotAllowed http.Handler,
+) func(branches ...RequestLineBranch[http.Handler]) http.Handler {
+	return func(branches ...RequestLineBranch[http.Handler]) http.Handler {
+		routes := compiler.RequestLineCompiler.RootWithMethodNotAllowed(missing, methodNotAllowed)(branches...)
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			match := routes(RequestLine{
+				Method: request.Method,
+				Host:   request.Host,
+				Path:   request.URL.Path,
+				Header: request.Header,
+				Query:  request.URL.Query(),
+			})
+			if len(match.Allowed) > 0 {
+				writer.Header().Set("Allow", strings.Join(match.Allowed, ", "))
+			}
+			endpoint := Chain(match.Middleware, match.Endpoint)
+			endpoint.ServeHTTP(writer, withRoute(request, match))
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package http_routing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func describingHandler(name string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		params := Params(request)
+		pairs := make([]string, 0, len(params))
+		for key, value := range params {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		fmt.Fprintf(writer, "%s %s", name, strings.Join(pairs, ","))
+	})
+}
+
+func TestHttpHandlerCompiler(t *testing.T) {
+	dsl := NewHttpHandlerCompiler()
+	handler := dsl.Root(describingHandler("Missing"))(
+		dsl.Path("/")(dsl.Get(describingHandler("IndexRender"))),
+		dsl.Path("/users")(
+			dsl.Post(describingHandler("ApiCreateUser")),
+			dsl.Param("user_id")(
+				dsl.Get(describingHandler("ApiFetchUser")),
+				dsl.Delete(describingHandler("ApiDeleteUser")),
+			),
+		),
+		dsl.Path("/pre_match")(
+			dsl.Param("first")(
+				dsl.Param("second")(
+					dsl.Get(describingHandler("ParamMatch")),
+				),
+			),
+		),
+	)
+	var tests = []struct {
+		name     string
+		method   string
+		target   string
+		expected string
+	}{
+		{
+			name:     "match missing route",
+			method:   "GET",
+			target:   "/idk",
+			expected: "Missing ",
+		},
+		{
+			name:     "match index route",
+			method:   "GET",
+			target:   "/",
+			expected: "IndexRender ",
+		},
+		{
+			name:     "match path and post",
+			method:   "POST",
+			target:   "/users",
+			expected: "ApiCreateUser ",
+		},
+		{
+			name:     "match param",
+			method:   "GET",
+			target:   "/users/1337",
+			expected: "ApiFetchUser user_id=1337",
+		},
+		{
+			name:     "match param ignoring query",
+			method:   "DELETE",
+			target:   "/users/1337?force=true",
+			expected: "ApiDeleteUser user_id=1337",
+		},
+		{
+			name:     "match multi param",
+			method:   "GET",
+			target:   "/pre_match/a/b",
+			expected: "ParamMatch first=a,second=b",
+		},
+		{
+			name:     "match missing method",
+			method:   "PUT",
+			target:   "/users/1337",
+			expected: "Missing ",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(test.method, test.target, nil))
+			if result := recorder.Body.String(); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestParamsWithoutRouting(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	if params := Params(request); len(params) != 0 {
+		t.Errorf("got %+v, want no params", params)
+	}
+	if param := Param(request, "user_id"); param != "" {
+		t.Errorf("got %q, want empty param", param)
+	}
+}
+
+func TestHttpHandlerCompilerMethodNotAllowed(t *testing.T) {
+	dsl := NewHttpHandlerCompiler()
+	handler := dsl.RootWithMethodNotAllowed(describingHandler("Missing"), describingHandler("NotAllowed"))(
+		dsl.Path("/users")(
+			dsl.Param("user_id")(
+				dsl.Get(describingHandler("ApiFetchUser")),
+				dsl.Delete(describingHandler("ApiDeleteUser")),
+			),
+		),
+	)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("PUT", "/users/1337", nil))
+	if result := recorder.Body.String(); result != "NotAllowed " {
+		t.Errorf("got %q, want %q", result, "NotAllowed ")
+	}
+	if allow := recorder.Header().Get("Allow"); allow != "GET, DELETE" {
+		t.Errorf("got Allow %q, want %q", allow, "GET, DELETE")
+	}
+}
+
+func TestHttpHandlerCompilerWithAutomaticMethods(t *testing.T) {
+	dsl := NewHttpHandlerCompilerWithAutomaticMethods()
+	handler := dsl.Root(describingHandler("Missing"))(
+		dsl.Path("/users")(
+			dsl.Param("user_id")(
+				dsl.Get(describingHandler("ApiFetchUser")),
+				dsl.Delete(describingHandler("ApiDeleteUser")),
+			),
+		),
+	)
+	var tests = []struct {
+		name     string
+		method   string
+		status   int
+		allow    string
+		expected string
+	}{
+		{
+			name:     "head answered by get",
+			method:   "HEAD",
+			status:   http.StatusOK,
+			expected: "ApiFetchUser user_id=1337",
+		},
+		{
+			name:   "synthesized options",
+			method: "OPTIONS",
+			status: http.StatusNoContent,
+			allow:  "GET, DELETE, HEAD, OPTIONS",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(test.method, "/users/1337", nil))
+			if recorder.Code != test.status {
+				t.Errorf("got status %d, want %d", recorder.Code, test.status)
+			}
+			if allow := recorder.Header().Get("Allow"); allow != test.allow {
+				t.Errorf("got Allow %q, want %q", allow, test.allow)
+			}
+			if result := recorder.Body.String(); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestHttpHandlerCompilerRouteName(t *testing.T) {
+	dsl := NewHttpHandlerCompiler()
+	naming := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, RouteName(request))
+	})
+	handler := dsl.Root(naming)(
+		dsl.Path("/users")(dsl.Param("user_id")(dsl.Named("users.fetch")(dsl.Get(naming)))),
+	)
+	var tests = []struct {
+		target   string
+		expected string
+	}{
+		{target: "/users/1337", expected: "users.fetch"},
+		{target: "/idk", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", test.target, nil))
+			if result := recorder.Body.String(); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestHttpHandlerCompilerHost(t *testing.T) {
+	dsl := NewHttpHandlerCompiler()
+	handler := dsl.Root(describingHandler("Missing"))(
+		dsl.Host("{tenant}.example.com")(dsl.Path("/users")(dsl.Get(describingHandler("TenantListUsers")))),
+	)
+	var tests = []struct {
+		host     string
+		expected string
+	}{
+		{host: "acme.example.com", expected: "TenantListUsers tenant=acme"},
+		{host: "example.org", expected: "Missing "},
+	}
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/users", nil)
+			request.Host = test.host
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if result := recorder.Body.String(); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestHttpHandlerCompilerWith(t *testing.T) {
+	dsl := NewHttpHandlerCompiler()
+	header := func(value string) Middleware[http.Handler] {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Add("X-Middleware", value)
+				next.ServeHTTP(writer, request)
+			})
+		}
+	}
+	handler := dsl.Root(describingHandler("Missing"))(
+		dsl.With(header("log"))(dsl.Path("/users")(
+			dsl.With(header("auth"))(dsl.Param("user_id")(dsl.Get(describingHandler("ApiFetchUser")))),
+		)),
+	)
+	var tests = []struct {
+		target   string
+		body     string
+		expected []string
+	}{
+		{target: "/users/1337", body: "ApiFetchUser user_id=1337", expected: []string{"log", "auth"}},
+		{target: "/idk", body: "Missing ", expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", test.target, nil))
+			if result := recorder.Body.String(); result != test.body {
+				t.Errorf("got %q, want %q", result, test.body)
+			}
+			if result := recorder.Header().Values("X-Middleware"); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestHttpHandlerCompilerMeta(t *testing.T) {
+	dsl := NewHttpHandlerCompiler()
+	scoped := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, Meta(request, "scope"), " ", len(RouteMeta(request)))
+	})
+	handler := dsl.Root(scoped)(
+		dsl.Meta("scope", "users:read")(dsl.Path("/users")(dsl.Get(scoped))),
+	)
+	var tests = []struct {
+		target   string
+		expected string
+	}{
+		{target: "/users", expected: "users:read 1"},
+		{target: "/idk", expected: "<nil> 0"},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", test.target, nil))
+			if result := recorder.Body.String(); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package http_routing
+
+type Middleware[Endpoint any] func(endpoint Endpoint) Endpoint
+
+// Chain wraps the endpoint so the first middleware is the outermost, matching
+// the order in which With collects middleware from the root down.
+func Chain[Endpoint any](middleware []Middleware[Endpoint], endpoint Endpoint) Endpoint {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		endpoint = middleware[i](endpoint)
+	}
+	return endpoint
+}
+
+func prependMiddleware[Endpoint any](
+	middleware []Middleware[Endpoint],
+	existing []Middleware[Endpoint],
+) []Middleware[Endpoint] {
+	out := make([]Middleware[Endpoint], 0, len(middleware)+len(existing))
+	out = append(out, middleware...)
+	return append(out, existing...)
+}
@@ -0,0 +1,66 @@
+package http_routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+type segmentCompiler[Branch any] interface {
+	Path(prefix string) func(branches ...Branch) Branch
+	Param(name string) func(branches ...Branch) Branch
+	ConstrainedParam(name string, constraint ParamConstraint) func(branches ...Branch) Branch
+	CatchAll(name string) func(branches ...Branch) Branch
+}
+
+func segmentCombinator[Branch any](
+	compiler segmentCompiler[Branch],
+	segment radixSegment,
+) func(branches ...Branch) Branch {
+	switch segment.kind {
+	case radixCatchAll:
+		return compiler.CatchAll(segment.value)
+	case radixParam:
+		if segment.constraint == nil {
+			return compiler.Param(segment.value)
+		}
+		return compiler.ConstrainedParam(segment.value, segment.constraint)
+	}
+	return compiler.Path(segment.value)
+}
+
+func parseMountPrefix(prefix string) ([]radixSegment, error) {
+	if prefix == "" || prefix == "/" {
+		return []radixSegment{{radixStatic, "", nil}}, nil
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		return nil, fmt.Errorf("mount prefix %q must start with /", prefix)
+	}
+	parsed, err := ParsePath(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("mount prefix %q: %w", prefix, err)
+	}
+	segments := make([]radixSegment, 0, len(parsed))
+	for _, segment := range parsed {
+		if segment.Kind == CatchAllSegment {
+			return nil, fmt.Errorf("mount prefix %q cannot contain a catch-all", prefix)
+		}
+		segments = append(segments, segment.radix())
+	}
+	return segments, nil
+}
+
+// mountPrefix nests branches under a prefix written in the same syntax as
+// FlatRouteTranspiler paths, so "/orgs/{org_id}/billing" becomes a Path, a Param
+// and another Path wrapped around the mounted branches.
+func mountPrefix[Branch any](compiler segmentCompiler[Branch], prefix string) func(branches ...Branch) Branch {
+	segments, err := parseMountPrefix(prefix)
+	if err != nil {
+		panic(err)
+	}
+	return func(branches ...Branch) Branch {
+		for i := len(segments) - 1; i >= 0; i-- {
+			branches = []Branch{segmentCombinator(compiler, segments[i])(branches...)}
+		}
+		return branches[0]
+	}
+}
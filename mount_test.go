@@ -0,0 +1,48 @@
+package http_routing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMountPrefix(t *testing.T) {
+	var tests = []struct {
+		prefix   string
+		expected []radixSegment
+		err      bool
+	}{
+		{prefix: "", expected: []radixSegment{{radixStatic, "", nil}}},
+		{prefix: "/", expected: []radixSegment{{radixStatic, "", nil}}},
+		{prefix: "/api/v1/", expected: []radixSegment{{radixStatic, "/api/v1", nil}}},
+		{
+			prefix: "/orgs/{org_id:int}/billing",
+			expected: []radixSegment{
+				{radixStatic, "/orgs", nil},
+				{radixParam, "org_id", IntConstraint()},
+				{radixStatic, "/billing", nil},
+			},
+		},
+		{
+			prefix: "/refs/{ref:regex(heads/[a-z]+)}/",
+			expected: []radixSegment{
+				{radixStatic, "/refs", nil},
+				{radixParam, "ref", RegexConstraint("heads/[a-z]+")},
+			},
+		},
+		{prefix: "billing", err: true},
+		{prefix: "/a/{x}{y}", err: true},
+		{prefix: "/orgs/{org_id:float}", err: true},
+		{prefix: "/static/{file...}", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.prefix, func(t *testing.T) {
+			result, err := parseMountPrefix(test.prefix)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if !test.err && !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
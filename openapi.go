@@ -0,0 +1,293 @@
+package http_routing
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type OpenApiSchema struct {
+	Type    string   `json:"type"`
+	Format  string   `json:"format,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+}
+
+type OpenApiParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   OpenApiSchema `json:"schema"`
+}
+
+type OpenApiOperation struct {
+	OperationId string             `json:"operationId,omitempty"`
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+	Parameters  []OpenApiParameter `json:"parameters,omitempty"`
+}
+
+type OpenApiPathItem map[string]OpenApiOperation
+
+type OpenApiPaths map[string]OpenApiPathItem
+
+func openApiSchema(constraint ParamConstraint) OpenApiSchema {
+	switch typed := constraint.(type) {
+	case intConstraint:
+		return OpenApiSchema{Type: "integer"}
+	case uuidConstraint:
+		return OpenApiSchema{Type: "string", Format: "uuid"}
+	case regexConstraint:
+		return OpenApiSchema{Type: "string", Pattern: "^(?:" + typed.pattern + ")$"}
+	case enumConstraint:
+		return OpenApiSchema{Type: "string", Enum: typed.values}
+	}
+	return OpenApiSchema{Type: "string"}
+}
+
+// openApiPath falls back to parsing Path for descriptions built by hand
+// without Segments, and to the raw path when that fails.
+func openApiPath[Endpoint any](route RouteDescription[Endpoint]) (string, []string) {
+	segments := route.Segments
+	if segments == nil {
+		parsed, err := parsePath(route.Path, route.Constraints)
+		if err != nil {
+			return route.Path, nil
+		}
+		segments = parsed
+	}
+	names := []string{}
+	for _, segment := range segments {
+		if segment.Kind != StaticSegment {
+			names = append(names, segment.Value)
+		}
+	}
+	return OpenApiPathStyle.Render(segments), names
+}
+
+var (
+	ErrOpenApiCollision         = errors.New("path and method already exported by an earlier route")
+	ErrOpenApiUnsupportedMethod = errors.New("method has no OpenAPI path item field")
+)
+
+// OpenApiSkippedRoute is a described route with no operation of its own in the
+// export.
+type OpenApiSkippedRoute struct {
+	Method string
+	Host   string
+	Path   string
+	Err    error
+}
+
+func (skipped OpenApiSkippedRoute) Error() string {
+	return skipped.Method + " " + skipped.Host + skipped.Path + ": " + skipped.Err.Error()
+}
+
+func (skipped OpenApiSkippedRoute) Unwrap() error {
+	return skipped.Err
+}
+
+type OpenApiSkippedRoutes []OpenApiSkippedRoute
+
+func (skipped OpenApiSkippedRoutes) Error() string {
+	messages := make([]string, 0, len(skipped))
+	for _, route := range skipped {
+		messages = append(messages, route.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+var openApiMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func isOpenApiMethod(method string) bool {
+	for _, supported := range openApiMethods {
+		if method == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// NewOpenApiPaths fills in routes described with AnyMethod last, as one
+// operation for each method OpenAPI can express that the path doesn't already
+// declare. Methods a path item has no field for, such as CONNECT or WebDAV
+// verbs, are skipped. A path item holds one operation per method, so routes told apart
+// only by host or predicates keep the first one declared, and the rest are
+// returned as OpenApiSkippedRoutes alongside the paths that were exported.
+// An operationId shared by several operations, as one endpoint under Any or
+// Methods gives, is suffixed with the lower-case method, as in Proxy_get.
+func NewOpenApiPaths[Endpoint any](
+	description Description[Endpoint],
+	operation func(endpoint Endpoint) OpenApiOperation,
+) (OpenApiPaths, error) {
+	paths := OpenApiPaths{}
+	skipped := OpenApiSkippedRoutes{}
+	for _, route := range description.Routes {
+		if route.Method != AnyMethod {
+			method := strings.ToLower(route.Method)
+			if !isOpenApiMethod(method) {
+				skipped = append(skipped, OpenApiSkippedRoute{route.Method, route.Host, route.Path, ErrOpenApiUnsupportedMethod})
+				continue
+			}
+			methods := []string{method}
+			if !addOpenApiOperation(paths, route, methods, operation) {
+				skipped = append(skipped, OpenApiSkippedRoute{route.Method, route.Host, route.Path, ErrOpenApiCollision})
+			}
+		}
+	}
+	for _, route := range description.Routes {
+		if route.Method == AnyMethod {
+			if !addOpenApiOperation(paths, route, openApiMethods, operation) {
+				skipped = append(skipped, OpenApiSkippedRoute{route.Method, route.Host, route.Path, ErrOpenApiCollision})
+			}
+		}
+	}
+	uniqueOperationIds(paths)
+	if len(skipped) > 0 {
+		return paths, skipped
+	}
+	return paths, nil
+}
+
+// uniqueOperationIds renames every operation whose id another one shares, and
+// numbers those still shared, such as one endpoint under two paths, in path
+// order.
+func uniqueOperationIds(paths OpenApiPaths) {
+	templates := make([]string, 0, len(paths))
+	counts := map[string]int{}
+	for template, item := range paths {
+		templates = append(templates, template)
+		for _, operation := range item {
+			counts[operation.OperationId]++
+		}
+	}
+	sort.Strings(templates)
+	used := map[string]bool{}
+	for _, template := range templates {
+		for _, method := range openApiMethods {
+			operation, ok := paths[template][method]
+			if !ok || operation.OperationId == "" || counts[operation.OperationId] < 2 {
+				continue
+			}
+			base := operation.OperationId + "_" + method
+			id := base
+			for n := 2; used[id] || counts[id] > 0; n++ {
+				id = base + "_" + strconv.Itoa(n)
+			}
+			used[id] = true
+			operation.OperationId = id
+			paths[template][method] = operation
+		}
+	}
+}
+
+func addOpenApiOperation[Endpoint any](
+	paths OpenApiPaths,
+	route RouteDescription[Endpoint],
+	methods []string,
+	operation func(endpoint Endpoint) OpenApiOperation,
+) bool {
+	template, names := openApiPath(route)
+	added := false
+	for _, method := range methods {
+		if _, ok := paths[template][method]; ok {
+			continue
+		}
+		if _, ok := paths[template]; !ok {
+			paths[template] = OpenApiPathItem{}
+		}
+		described := operation(route.Endpoint)
+		for _, name := range names {
+			described.Parameters = append(described.Parameters, OpenApiParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   openApiSchema(route.Constraints[name]),
+			})
+		}
+		paths[template][method] = described
+		added = true
+	}
+	return added
+}
+
+func (paths OpenApiPaths) JSON() ([]byte, error) {
+	return json.MarshalIndent(paths, "", "  ")
+}
+
+func (paths OpenApiPaths) YAML() ([]byte, error) {
+	encoded, err := json.Marshal(paths)
+	if err != nil {
+		return nil, err
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, err
+	}
+	if len(generic) == 0 {
+		return []byte("{}\n"), nil
+	}
+	return appendYamlMapping(nil, generic, "", ""), nil
+}
+
+var yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func yamlKey(key string) string {
+	if yamlPlainKey.MatchString(key) {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+func appendYamlMapping(out []byte, mapping map[string]interface{}, firstIndent string, indent string) []byte {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			out = append(out, firstIndent...)
+		} else {
+			out = append(out, indent...)
+		}
+		out = append(out, yamlKey(key)+":"...)
+		out = appendYamlValue(out, mapping[key], indent+"  ")
+	}
+	return out
+}
+
+func appendYamlValue(out []byte, value interface{}, indent string) []byte {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if len(typed) == 0 {
+			return append(out, " {}\n"...)
+		}
+		out = append(out, '\n')
+		return appendYamlMapping(out, typed, indent, indent)
+	case []interface{}:
+		if len(typed) == 0 {
+			return append(out, " []\n"...)
+		}
+		out = append(out, '\n')
+		for _, item := range typed {
+			if mapping, ok := item.(map[string]interface{}); ok && len(mapping) > 0 {
+				out = appendYamlMapping(out, mapping, indent+"- ", indent+"  ")
+			} else {
+				out = append(out, indent+"-"...)
+				out = appendYamlValue(out, item, indent+"  ")
+			}
+		}
+		return out
+	}
+	scalar, _ := json.Marshal(value)
+	out = append(out, ' ')
+	out = append(out, scalar...)
+	return append(out, '\n')
+}
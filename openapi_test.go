@@ -0,0 +1,318 @@
+package http_routing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func describeOpenApiOperation(endpoint string) OpenApiOperation {
+	return OpenApiOperation{OperationId: endpoint, Tags: []string{"users"}}
+}
+
+func makeOpenApiDescription() Description[string] {
+	dsl := NewDescriptionCompiler[string]()
+	return dsl.Root("Missing")(
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.ConstrainedParam("user_id", IntConstraint())(
+				dsl.Get("ApiFetchUser"),
+				dsl.Delete("ApiDeleteUser"),
+			),
+		),
+		dsl.Path("/static")(dsl.CatchAll("file")(dsl.Get("StaticFile"))),
+	)
+}
+
+func TestNewOpenApiPaths(t *testing.T) {
+	result, err := NewOpenApiPaths(makeOpenApiDescription(), describeOpenApiOperation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userId := OpenApiParameter{Name: "user_id", In: "path", Required: true, Schema: OpenApiSchema{Type: "integer"}}
+	expected := OpenApiPaths{
+		"/users": {
+			"post": {OperationId: "ApiCreateUser", Tags: []string{"users"}},
+		},
+		"/users/{user_id}": {
+			"get":    {OperationId: "ApiFetchUser", Tags: []string{"users"}, Parameters: []OpenApiParameter{userId}},
+			"delete": {OperationId: "ApiDeleteUser", Tags: []string{"users"}, Parameters: []OpenApiParameter{userId}},
+		},
+		"/static/{file}": {
+			"get": {
+				OperationId: "StaticFile",
+				Tags:        []string{"users"},
+				Parameters: []OpenApiParameter{
+					{Name: "file", In: "path", Required: true, Schema: OpenApiSchema{Type: "string"}},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestNewOpenApiPathsWithoutSegments(t *testing.T) {
+	description := Description[string]{Routes: []RouteDescription[string]{
+		{Method: "GET", Endpoint: "IndexRender"},
+		{Method: "GET", Path: "/users/{user_id:int}", Endpoint: "ApiFetchUser"},
+	}}
+	result, err := NewOpenApiPaths(description, func(endpoint string) OpenApiOperation {
+		return OpenApiOperation{OperationId: endpoint}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := OpenApiPaths{
+		"/": {"get": {OperationId: "IndexRender"}},
+		"/users/{user_id}": {"get": {
+			OperationId: "ApiFetchUser",
+			Parameters: []OpenApiParameter{
+				{Name: "user_id", In: "path", Required: true, Schema: OpenApiSchema{Type: "string"}},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestNewOpenApiPathsAnyMethod(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("missing")(
+		dsl.Path("/proxy")(dsl.Any("Proxy"), dsl.Get("ProxyStatus")),
+	)
+	result, err := NewOpenApiPaths(description, func(endpoint string) OpenApiOperation {
+		return OpenApiOperation{OperationId: endpoint}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := OpenApiPaths{"/proxy": {
+		"get":     {OperationId: "ProxyStatus"},
+		"put":     {OperationId: "Proxy_put"},
+		"post":    {OperationId: "Proxy_post"},
+		"delete":  {OperationId: "Proxy_delete"},
+		"options": {OperationId: "Proxy_options"},
+		"head":    {OperationId: "Proxy_head"},
+		"patch":   {OperationId: "Proxy_patch"},
+		"trace":   {OperationId: "Proxy_trace"},
+	}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestNewOpenApiPathsUniqueOperationIds(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("missing")(
+		dsl.Path("/files")(dsl.Methods([]string{"GET", "HEAD"}, "FetchFile")),
+		dsl.Path("/a")(dsl.Get("Fetch")),
+		dsl.Path("/b")(dsl.Get("Fetch")),
+		dsl.Path("/c")(dsl.Get("Fetch_get")),
+	)
+	result, err := NewOpenApiPaths(description, func(endpoint string) OpenApiOperation {
+		return OpenApiOperation{OperationId: endpoint}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := OpenApiPaths{
+		"/files": {"get": {OperationId: "FetchFile_get"}, "head": {OperationId: "FetchFile_head"}},
+		"/a":     {"get": {OperationId: "Fetch_get_2"}},
+		"/b":     {"get": {OperationId: "Fetch_get_3"}},
+		"/c":     {"get": {OperationId: "Fetch_get"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestNewOpenApiPathsCollisions(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("missing")(
+		dsl.Host("{tenant}.example.com")(dsl.Path("/x")(dsl.Get("A"))),
+		dsl.Path("/x")(dsl.Get("B")),
+		dsl.Path("/users")(
+			dsl.Where(AcceptPredicate("application/vnd.v2+json"))(dsl.Get("ListUsersV2")),
+			dsl.Get("ListUsers"),
+		),
+	)
+	result, err := NewOpenApiPaths(description, func(endpoint string) OpenApiOperation {
+		return OpenApiOperation{OperationId: endpoint}
+	})
+	expected := OpenApiPaths{
+		"/x":     {"get": {OperationId: "A"}},
+		"/users": {"get": {OperationId: "ListUsersV2"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+	skipped, ok := err.(OpenApiSkippedRoutes)
+	if !ok || len(skipped) != 2 {
+		t.Fatalf("got %v, want two skipped routes", err)
+	}
+	message := "GET /x: path and method already exported by an earlier route\n" +
+		"GET /users: path and method already exported by an earlier route"
+	if err.Error() != message {
+		t.Errorf("got %q, want %q", err.Error(), message)
+	}
+	if !errors.Is(skipped[0], ErrOpenApiCollision) {
+		t.Errorf("got %v, want %v", skipped[0], ErrOpenApiCollision)
+	}
+}
+
+func TestNewOpenApiPathsUnsupportedMethods(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("missing")(
+		dsl.Path("/files")(
+			dsl.Method("PROPFIND", "DavListFiles"),
+			dsl.Connect("Tunnel"),
+			dsl.Get("FetchFile"),
+		),
+	)
+	result, err := NewOpenApiPaths(description, func(endpoint string) OpenApiOperation {
+		return OpenApiOperation{OperationId: endpoint}
+	})
+	expected := OpenApiPaths{"/files": {"get": {OperationId: "FetchFile"}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+	skipped, ok := err.(OpenApiSkippedRoutes)
+	if !ok || len(skipped) != 2 {
+		t.Fatalf("got %v, want two skipped routes", err)
+	}
+	for _, route := range skipped {
+		if !errors.Is(route, ErrOpenApiUnsupportedMethod) {
+			t.Errorf("got %v, want %v", route, ErrOpenApiUnsupportedMethod)
+		}
+	}
+}
+
+func TestOpenApiSchemas(t *testing.T) {
+	var tests = []struct {
+		name       string
+		constraint ParamConstraint
+		expected   OpenApiSchema
+	}{
+		{name: "unconstrained", constraint: nil, expected: OpenApiSchema{Type: "string"}},
+		{name: "int", constraint: IntConstraint(), expected: OpenApiSchema{Type: "integer"}},
+		{name: "uuid", constraint: UuidConstraint(), expected: OpenApiSchema{Type: "string", Format: "uuid"}},
+		{
+			name:       "regex",
+			constraint: RegexConstraint("[a-z]+"),
+			expected:   OpenApiSchema{Type: "string", Pattern: "^(?:[a-z]+)$"},
+		},
+		{
+			name:       "enum",
+			constraint: EnumConstraint("draft", "published"),
+			expected:   OpenApiSchema{Type: "string", Enum: []string{"draft", "published"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := openApiSchema(test.constraint); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestOpenApiPathsJSON(t *testing.T) {
+	dsl := NewDescriptionCompiler[string]()
+	description := dsl.Root("Missing")(dsl.Path("/users")(dsl.Param("user_id")(dsl.Get("ApiFetchUser"))))
+	paths, err := NewOpenApiPaths(description, describeOpenApiOperation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := paths.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "/users/{user_id}": {
+    "get": {
+      "operationId": "ApiFetchUser",
+      "tags": [
+        "users"
+      ],
+      "parameters": [
+        {
+          "name": "user_id",
+          "in": "path",
+          "required": true,
+          "schema": {
+            "type": "string"
+          }
+        }
+      ]
+    }
+  }
+}`
+	if string(result) != expected {
+		t.Errorf("got %s, want %s", result, expected)
+	}
+}
+
+func TestOpenApiPathsYAML(t *testing.T) {
+	paths, err := NewOpenApiPaths(makeOpenApiDescription(), describeOpenApiOperation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := paths.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `"/static/{file}":
+  get:
+    operationId: "StaticFile"
+    parameters:
+      - in: "path"
+        name: "file"
+        required: true
+        schema:
+          type: "string"
+    tags:
+      - "users"
+"/users":
+  post:
+    operationId: "ApiCreateUser"
+    tags:
+      - "users"
+"/users/{user_id}":
+  delete:
+    operationId: "ApiDeleteUser"
+    parameters:
+      - in: "path"
+        name: "user_id"
+        required: true
+        schema:
+          type: "integer"
+    tags:
+      - "users"
+  get:
+    operationId: "ApiFetchUser"
+    parameters:
+      - in: "path"
+        name: "user_id"
+        required: true
+        schema:
+          type: "integer"
+    tags:
+      - "users"
+`
+	if string(result) != expected {
+		t.Errorf("got %s, want %s", result, expected)
+	}
+}
+
+func TestEmptyOpenApiPathsYAML(t *testing.T) {
+	result, err := OpenApiPaths{}.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != "{}\n" {
+		t.Errorf("got %q, want %q", result, "{}\n")
+	}
+}
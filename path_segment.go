@@ -0,0 +1,232 @@
+package http_routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+type PathSegmentKind int64
+
+const (
+	StaticSegment PathSegmentKind = iota
+	ParamSegment
+	CatchAllSegment
+)
+
+// PathSegment is one piece of a described path. Static values are the prefixes
+// handed to Path, slash included; params and catch-alls carry only their name
+// and always consume the slash before them.
+type PathSegment struct {
+	Kind       PathSegmentKind
+	Value      string
+	Constraint ParamConstraint
+}
+
+func (segment PathSegment) String() string {
+	switch segment.Kind {
+	case ParamSegment:
+		if segment.Constraint == nil {
+			return "/{" + segment.Value + "}"
+		}
+		return "/{" + segment.Value + ":" + segment.Constraint.String() + "}"
+	case CatchAllSegment:
+		return "/{" + segment.Value + "...}"
+	}
+	return segment.Value
+}
+
+type PathStyle int64
+
+const (
+	// BracePathStyle writes params as /{id} and catch-alls as /{path...}.
+	BracePathStyle PathStyle = iota
+	// ColonPathStyle writes params as /:id and catch-alls as /*path.
+	ColonPathStyle
+	// AnglePathStyle writes params as /<id> and catch-alls as /<path:path>.
+	AnglePathStyle
+	// OpenApiPathStyle writes params and catch-alls alike as /{id}, and the
+	// empty path as /.
+	OpenApiPathStyle
+)
+
+func (style PathStyle) param(name string) string {
+	switch style {
+	case ColonPathStyle:
+		return "/:" + name
+	case AnglePathStyle:
+		return "/<" + name + ">"
+	}
+	return "/{" + name + "}"
+}
+
+func (style PathStyle) catchAll(name string) string {
+	switch style {
+	case BracePathStyle:
+		return "/{" + name + "...}"
+	case ColonPathStyle:
+		return "/*" + name
+	case AnglePathStyle:
+		return "/<path:" + name + ">"
+	}
+	return "/{" + name + "}"
+}
+
+// Render drops constraints, which none of the styles can express; use
+// RenderPath to keep them.
+func (style PathStyle) Render(segments []PathSegment) string {
+	var out strings.Builder
+	for _, segment := range segments {
+		switch segment.Kind {
+		case ParamSegment:
+			out.WriteString(style.param(segment.Value))
+		case CatchAllSegment:
+			out.WriteString(style.catchAll(segment.Value))
+		default:
+			out.WriteString(segment.Value)
+		}
+	}
+	if out.Len() == 0 && style == OpenApiPathStyle {
+		return "/"
+	}
+	return out.String()
+}
+
+func (segment PathSegment) radix() radixSegment {
+	return radixSegment{radixSegmentKind(segment.Kind), segment.Value, segment.Constraint}
+}
+
+func RenderPath(segments []PathSegment) string {
+	var out strings.Builder
+	for _, segment := range segments {
+		out.WriteString(segment.String())
+	}
+	return out.String()
+}
+
+// prependPathSegment merges adjacent static segments and drops empty ones, so
+// the segments DescriptionCompiler builds are exactly those ParsePath reads
+// back from the rendered path.
+func prependPathSegment(segment PathSegment, segments []PathSegment) []PathSegment {
+	if segment.Kind == StaticSegment {
+		if segment.Value == "" {
+			return segments
+		}
+		if len(segments) > 0 && segments[0].Kind == StaticSegment {
+			out := make([]PathSegment, len(segments))
+			copy(out, segments)
+			out[0].Value = segment.Value + out[0].Value
+			return out
+		}
+	}
+	out := make([]PathSegment, 0, len(segments)+1)
+	out = append(out, segment)
+	return append(out, segments...)
+}
+
+func closingBrace(path string) int {
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ParsePath reads the path grammar shared by FlatRouteTranspiler and
+// DescriptionCompiler:
+//
+//	path     = *( static / param / catchall )
+//	param    = "/{" name [ ":" constraint ] "}"
+//	catchall = "/{" name "...}"
+//
+// where static is any run of text without braces and name is any non-empty
+// text without ":" or braces. Braces inside a constraint, as in a regex
+// repetition, must balance; a second param straight after the first, as in
+// /{x}{y}, is ErrMalformedSegment.
+func ParsePath(path string) ([]PathSegment, error) {
+	return parsePath(path, nil)
+}
+
+// parsePath takes constraints from the map before parsing their rendered form,
+// so custom ParamConstraint implementations survive the trip.
+func parsePath(path string, constraints map[string]ParamConstraint) ([]PathSegment, error) {
+	segments, errs := scanPath(path, constraints)
+	if len(errs) > 0 {
+		return nil, errs[0].err
+	}
+	return segments, nil
+}
+
+// pathError is a problem found by scanPath, placed before the segment at index
+// at.
+type pathError struct {
+	at  int
+	err error
+}
+
+// scanPath reads past each malformed piece of path so that every problem is
+// reported. Params it can't read keep their place under whatever name they
+// have, possibly empty, and malformed statics are left out.
+func scanPath(path string, constraints map[string]ParamConstraint) ([]PathSegment, []pathError) {
+	segments := []PathSegment{}
+	errs := []pathError{}
+	fail := func(err error) {
+		errs = append(errs, pathError{len(segments), err})
+	}
+	for path != "" {
+		param := strings.Index(path, "/{")
+		if param != 0 {
+			if param == -1 {
+				param = len(path)
+			}
+			static := path[:param]
+			path = path[param:]
+			switch {
+			case strings.Count(static, "{") != strings.Count(static, "}"):
+				fail(fmt.Errorf("%w %q", ErrUnbalancedBraces, static))
+			case strings.ContainsAny(static, "{}"):
+				fail(fmt.Errorf("%w, not %q", ErrMalformedSegment, static))
+			default:
+				segments = append(segments, PathSegment{StaticSegment, static, nil})
+			}
+			continue
+		}
+		end := closingBrace(path)
+		if end == -1 {
+			fail(fmt.Errorf("%w %q", ErrUnbalancedBraces, path[1:]))
+			break
+		}
+		body := path[2:end]
+		path = path[end+1:]
+		if name := strings.TrimSuffix(body, "..."); name != body {
+			if name == "" {
+				fail(fmt.Errorf("%w %q", ErrEmptyParamName, "{"+body+"}"))
+			}
+			segments = append(segments, PathSegment{CatchAllSegment, name, nil})
+			continue
+		}
+		name, spec, constrained := strings.Cut(body, ":")
+		if name == "" {
+			fail(fmt.Errorf("%w %q", ErrEmptyParamName, "{"+body+"}"))
+		}
+		var constraint ParamConstraint
+		if constrained {
+			if known, ok := constraints[name]; ok {
+				constraint = known
+			} else if parsed, err := parseParamConstraint(spec); err != nil {
+				fail(fmt.Errorf("%w: %v", ErrMalformedPath, err))
+			} else {
+				constraint = parsed
+			}
+		}
+		segments = append(segments, PathSegment{ParamSegment, name, constraint})
+	}
+	return segments, errs
+}
@@ -0,0 +1,92 @@
+package http_routing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected []PathSegment
+		is       error
+	}{
+		{path: "", expected: []PathSegment{}},
+		{path: "/", expected: []PathSegment{{StaticSegment, "/", nil}}},
+		{
+			path: "/files/{path...}",
+			expected: []PathSegment{
+				{StaticSegment, "/files", nil},
+				{CatchAllSegment, "path", nil},
+			},
+		},
+		{
+			path: "/codes/{code:regex([a-z]{3})}",
+			expected: []PathSegment{
+				{StaticSegment, "/codes", nil},
+				{ParamSegment, "code", RegexConstraint("[a-z]{3}")},
+			},
+		},
+		{path: "/users/{user_id", is: ErrUnbalancedBraces},
+		{path: "/users/user_id}", is: ErrUnbalancedBraces},
+		{
+			path: "/refs/{ref:regex(heads/[a-z]+)}",
+			expected: []PathSegment{
+				{StaticSegment, "/refs", nil},
+				{ParamSegment, "ref", RegexConstraint("heads/[a-z]+")},
+			},
+		},
+		{path: "/a/{x}{y}", is: ErrMalformedSegment},
+		{path: "/users/{}", is: ErrEmptyParamName},
+		{path: "/users/{:int}", is: ErrEmptyParamName},
+		{path: "/users/{user_id:float}", is: ErrMalformedPath},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			segments, err := ParsePath(test.path)
+			if test.is != nil {
+				if !errors.Is(err, test.is) {
+					t.Errorf("got %v, want %v", err, test.is)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(segments, test.expected) {
+				t.Errorf("got %v, want %v", segments, test.expected)
+			}
+			if rendered := RenderPath(segments); rendered != test.path {
+				t.Errorf("rendered %q, want %q", rendered, test.path)
+			}
+		})
+	}
+}
+
+func TestPathStyleRender(t *testing.T) {
+	segments := []PathSegment{
+		{StaticSegment, "/users", nil},
+		{ParamSegment, "user_id", IntConstraint()},
+		{StaticSegment, "/files", nil},
+		{CatchAllSegment, "path", nil},
+	}
+	var tests = []struct {
+		style    PathStyle
+		expected string
+		empty    string
+	}{
+		{style: BracePathStyle, expected: "/users/{user_id}/files/{path...}"},
+		{style: ColonPathStyle, expected: "/users/:user_id/files/*path"},
+		{style: AnglePathStyle, expected: "/users/<user_id>/files/<path:path>"},
+		{style: OpenApiPathStyle, expected: "/users/{user_id}/files/{path}", empty: "/"},
+	}
+	for _, test := range tests {
+		if result := test.style.Render(segments); result != test.expected {
+			t.Errorf("style %d: got %q, want %q", test.style, result, test.expected)
+		}
+		if result := test.style.Render(nil); result != test.empty {
+			t.Errorf("style %d: got %q for the empty path, want %q", test.style, result, test.empty)
+		}
+	}
+}
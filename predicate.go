@@ -0,0 +1,156 @@
+package http_routing
+
+import (
+	"strings"
+)
+
+type RequestPredicate interface {
+	Matches(line RequestLine) bool
+	String() string
+}
+
+type headerPredicate struct {
+	name  string
+	value string
+}
+
+func HeaderPredicate(name string, value string) RequestPredicate {
+	return headerPredicate{name, value}
+}
+
+func (predicate headerPredicate) Matches(line RequestLine) bool {
+	for _, value := range line.Header.Values(predicate.name) {
+		if value == predicate.value {
+			return true
+		}
+	}
+	return false
+}
+
+func (predicate headerPredicate) String() string {
+	return "header(" + predicate.name + "=" + predicate.value + ")"
+}
+
+type queryPredicate struct {
+	name  string
+	value string
+}
+
+func QueryPredicate(name string, value string) RequestPredicate {
+	return queryPredicate{name, value}
+}
+
+func (predicate queryPredicate) Matches(line RequestLine) bool {
+	for _, value := range line.Query[predicate.name] {
+		if value == predicate.value {
+			return true
+		}
+	}
+	return false
+}
+
+func (predicate queryPredicate) String() string {
+	return "query(" + predicate.name + "=" + predicate.value + ")"
+}
+
+func mediaType(value string) string {
+	mediaType, _, _ := strings.Cut(value, ";")
+	return strings.TrimSpace(mediaType)
+}
+
+type contentTypePredicate struct {
+	mediaType string
+}
+
+func ContentTypePredicate(mediaType string) RequestPredicate {
+	return contentTypePredicate{mediaType}
+}
+
+func (predicate contentTypePredicate) Matches(line RequestLine) bool {
+	return strings.EqualFold(mediaType(line.Header.Get("Content-Type")), predicate.mediaType)
+}
+
+func (predicate contentTypePredicate) String() string {
+	return "content-type(" + predicate.mediaType + ")"
+}
+
+type acceptPredicate struct {
+	mediaType string
+}
+
+// AcceptPredicate matches when the Accept header explicitly lists the media
+// type; wildcards such as */* do not match, so routes selected by media type
+// have to be asked for by name.
+func AcceptPredicate(mediaType string) RequestPredicate {
+	return acceptPredicate{mediaType}
+}
+
+func refusedMediaRange(value string) bool {
+	_, params, _ := strings.Cut(value, ";")
+	for params != "" {
+		var param string
+		param, params, _ = strings.Cut(params, ";")
+		key, quality, _ := strings.Cut(param, "=")
+		if strings.TrimSpace(key) == "q" && strings.Trim(strings.TrimSpace(quality), "0.") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (predicate acceptPredicate) Matches(line RequestLine) bool {
+	for _, header := range line.Header.Values("Accept") {
+		for header != "" {
+			var value string
+			value, header, _ = strings.Cut(header, ",")
+			if strings.EqualFold(mediaType(value), predicate.mediaType) && !refusedMediaRange(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (predicate acceptPredicate) String() string {
+	return "accept(" + predicate.mediaType + ")"
+}
+
+func matchesPredicates(predicates []RequestPredicate, line RequestLine) bool {
+	for _, predicate := range predicates {
+		if !predicate.Matches(line) {
+			return false
+		}
+	}
+	return true
+}
+
+func samePredicates(a []RequestPredicate, b []RequestPredicate) bool {
+	return len(a) == len(b) && coversPredicates(a, b)
+}
+
+func coversPredicates(a []RequestPredicate, b []RequestPredicate) bool {
+	for _, predicate := range a {
+		found := false
+		for _, other := range b {
+			if predicate.String() == other.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func renderPredicates(predicates []RequestPredicate) string {
+	if len(predicates) == 0 {
+		return ""
+	}
+	rendered := make([]string, 0, len(predicates))
+	for _, predicate := range predicates {
+		rendered = append(rendered, predicate.String())
+	}
+	return " [" + strings.Join(rendered, " ") + "]"
+}
@@ -0,0 +1,45 @@
+package http_routing
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRequestPredicates(t *testing.T) {
+	header := func(pairs ...string) RequestLine {
+		line := RequestLine{Header: http.Header{}}
+		for i := 0; i < len(pairs); i += 2 {
+			line.Header.Add(pairs[i], pairs[i+1])
+		}
+		return line
+	}
+	var tests = []struct {
+		name      string
+		predicate RequestPredicate
+		line      RequestLine
+		expected  bool
+	}{
+		{name: "header", predicate: HeaderPredicate("X-Version", "2"), line: header("X-Version", "2"), expected: true},
+		{name: "repeated header", predicate: HeaderPredicate("X-Version", "2"), line: header("X-Version", "1", "X-Version", "2"), expected: true},
+		{name: "header mismatch", predicate: HeaderPredicate("X-Version", "2"), line: header("X-Version", "1"), expected: false},
+		{name: "missing header", predicate: HeaderPredicate("X-Version", "2"), line: RequestLine{}, expected: false},
+		{name: "query", predicate: QueryPredicate("beta", "1"), line: RequestLine{Query: url.Values{"beta": {"1"}}}, expected: true},
+		{name: "query mismatch", predicate: QueryPredicate("beta", "1"), line: RequestLine{Query: url.Values{"beta": {"0"}}}, expected: false},
+		{name: "missing query", predicate: QueryPredicate("beta", "1"), line: RequestLine{}, expected: false},
+		{name: "content type", predicate: ContentTypePredicate("application/json"), line: header("Content-Type", "application/json"), expected: true},
+		{name: "content type with params", predicate: ContentTypePredicate("application/json"), line: header("Content-Type", "Application/JSON; charset=utf-8"), expected: true},
+		{name: "content type mismatch", predicate: ContentTypePredicate("application/json"), line: header("Content-Type", "text/plain"), expected: false},
+		{name: "accept", predicate: AcceptPredicate("application/vnd.v2+json"), line: header("Accept", "application/vnd.v2+json"), expected: true},
+		{name: "accept in list", predicate: AcceptPredicate("application/vnd.v2+json"), line: header("Accept", "text/html, application/vnd.v2+json;q=0.9"), expected: true},
+		{name: "accept refused", predicate: AcceptPredicate("application/vnd.v2+json"), line: header("Accept", "application/vnd.v2+json;q=0"), expected: false},
+		{name: "accept wildcard", predicate: AcceptPredicate("application/vnd.v2+json"), line: header("Accept", "*/*"), expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.predicate.Matches(test.line); result != test.expected {
+				t.Errorf("got %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
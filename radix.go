@@ -0,0 +1,509 @@
+package http_routing
+
+// RadixCompiler matches static prefixes before constrained params, constrained
+// params before other params and params before catch-alls, falling back when a
+// branch fails, rather than matching in declaration order.
+type RadixCompiler[Endpoint any] struct{}
+
+func NewRadixCompiler[Endpoint any]() Compiler[Endpoint, []RadixRoute[Endpoint], RequestLineRoot[Endpoint]] {
+	return RadixCompiler[Endpoint]{}
+}
+
+type radixSegmentKind int64
+
+const (
+	radixStatic radixSegmentKind = iota
+	radixParam
+	radixCatchAll
+)
+
+type radixSegment struct {
+	kind       radixSegmentKind
+	value      string
+	constraint ParamConstraint
+}
+
+type RadixRoute[Endpoint any] struct {
+	method     string
+	segments   []radixSegment
+	endpoint   Endpoint
+	name       string
+	host       *hostPattern
+	predicates []RequestPredicate
+	middleware []Middleware[Endpoint]
+	meta       map[string]interface{}
+}
+
+func (route RadixRoute[Endpoint]) prefixedWith(segment radixSegment) RadixRoute[Endpoint] {
+	if route.host != nil && segment.kind != radixStatic && route.host.capturesName(segment.value) {
+		panic(hostParamCollision(route.host.pattern, segment.value))
+	}
+	segments := make([]radixSegment, 0, len(route.segments)+1)
+	segments = append(segments, segment)
+	segments = append(segments, route.segments...)
+	route.segments = segments
+	return route
+}
+
+func (route RadixRoute[Endpoint]) named(name string) RadixRoute[Endpoint] {
+	if route.name == "" {
+		route.name = name
+	}
+	return route
+}
+
+func (route RadixRoute[Endpoint]) hosted(host hostPattern) RadixRoute[Endpoint] {
+	if route.host != nil {
+		panic(nestedHost(host.pattern))
+	}
+	for _, segment := range route.segments {
+		if segment.kind != radixStatic && host.capturesName(segment.value) {
+			panic(hostParamCollision(host.pattern, segment.value))
+		}
+	}
+	route.host = &host
+	return route
+}
+
+func (route RadixRoute[Endpoint]) prefixedWithPredicate(predicate RequestPredicate) RadixRoute[Endpoint] {
+	predicates := make([]RequestPredicate, 0, len(route.predicates)+1)
+	predicates = append(predicates, predicate)
+	route.predicates = append(predicates, route.predicates...)
+	return route
+}
+
+func (route RadixRoute[Endpoint]) prefixedWithMiddleware(middleware []Middleware[Endpoint]) RadixRoute[Endpoint] {
+	route.middleware = prependMiddleware(middleware, route.middleware)
+	return route
+}
+
+func (route RadixRoute[Endpoint]) withMeta(key string, value interface{}) RadixRoute[Endpoint] {
+	route.meta = withOuterMeta(route.meta, key, value)
+	return route
+}
+
+type radixLeaf[Endpoint any] struct {
+	endpoint   Endpoint
+	name       string
+	host       *hostPattern
+	predicates []RequestPredicate
+	middleware []Middleware[Endpoint]
+	meta       map[string]interface{}
+}
+
+func (leaf radixLeaf[Endpoint]) sameConditions(route RadixRoute[Endpoint]) bool {
+	if !samePredicates(leaf.predicates, route.predicates) {
+		return false
+	}
+	if leaf.host == nil || route.host == nil {
+		return leaf.host == nil && route.host == nil
+	}
+	return leaf.host.pattern == route.host.pattern
+}
+
+func (leaf radixLeaf[Endpoint]) match(line RequestLine, captures RequestLineParams) (RequestLineParams, bool) {
+	if !matchesPredicates(leaf.predicates, line) {
+		return captures, false
+	}
+	if leaf.host == nil {
+		return captures, true
+	}
+	return leaf.host.match(line.Host, captures)
+}
+
+type radixCapturing[Endpoint any] struct {
+	name       string
+	constraint ParamConstraint
+	node       *radixNode[Endpoint]
+}
+
+type radixNode[Endpoint any] struct {
+	prefix    string
+	statics   []*radixNode[Endpoint]
+	params    []radixCapturing[Endpoint]
+	catchAlls []radixCapturing[Endpoint]
+	methods   map[string][]radixLeaf[Endpoint]
+	allowed   []string
+}
+
+func commonPrefixLength(a string, b string) int {
+	length := len(a)
+	if len(b) < length {
+		length = len(b)
+	}
+	for i := 0; i < length; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return length
+}
+
+func (node *radixNode[Endpoint]) insertStatic(prefix string) *radixNode[Endpoint] {
+	if prefix == "" {
+		return node
+	}
+	for i, child := range node.statics {
+		common := commonPrefixLength(child.prefix, prefix)
+		if common == 0 {
+			continue
+		}
+		if common < len(child.prefix) {
+			split := &radixNode[Endpoint]{prefix: child.prefix[:common], statics: []*radixNode[Endpoint]{child}}
+			child.prefix = child.prefix[common:]
+			node.statics[i] = split
+			child = split
+		}
+		return child.insertStatic(prefix[common:])
+	}
+	child := &radixNode[Endpoint]{prefix: prefix}
+	node.statics = append(node.statics, child)
+	return child
+}
+
+func sameConstraint(a ParamConstraint, b ParamConstraint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.String() == b.String()
+}
+
+func insertCapturing[Endpoint any](
+	capturings []radixCapturing[Endpoint],
+	name string,
+	constraint ParamConstraint,
+) ([]radixCapturing[Endpoint], *radixNode[Endpoint]) {
+	position := len(capturings)
+	for i, capturing := range capturings {
+		if capturing.name == name && sameConstraint(capturing.constraint, constraint) {
+			return capturings, capturing.node
+		}
+		if constraint != nil && capturing.constraint == nil && i < position {
+			position = i
+		}
+	}
+	child := &radixNode[Endpoint]{}
+	capturings = append(capturings, radixCapturing[Endpoint]{})
+	copy(capturings[position+1:], capturings[position:])
+	capturings[position] = radixCapturing[Endpoint]{name, constraint, child}
+	return capturings, child
+}
+
+func (node *radixNode[Endpoint]) insert(route RadixRoute[Endpoint]) {
+	current := node
+	for _, segment := range route.segments {
+		switch segment.kind {
+		case radixStatic:
+			current = current.insertStatic(segment.value)
+		case radixParam:
+			current.params, current = insertCapturing(current.params, segment.value, segment.constraint)
+		case radixCatchAll:
+			current.catchAlls, current = insertCapturing(current.catchAlls, segment.value, nil)
+		}
+	}
+	if current.methods == nil {
+		current.methods = map[string][]radixLeaf[Endpoint]{}
+	}
+	leaves, ok := current.methods[route.method]
+	if !ok && route.method != AnyMethod {
+		current.allowed = append(current.allowed, route.method)
+	}
+	for _, leaf := range leaves {
+		if leaf.sameConditions(route) {
+			return
+		}
+	}
+	current.methods[route.method] = append(leaves, radixLeaf[Endpoint]{
+		route.endpoint,
+		route.name,
+		route.host,
+		route.predicates,
+		route.middleware,
+		route.meta,
+	})
+}
+
+func (node *radixNode[Endpoint]) lookupLeaf(
+	method string,
+	line RequestLine,
+	captures RequestLineParams,
+) (radixLeaf[Endpoint], RequestLineParams, bool) {
+	for _, leaf := range node.methods[method] {
+		if found, ok := leaf.match(line, captures); ok {
+			return leaf, found, true
+		}
+	}
+	return radixLeaf[Endpoint]{}, captures, false
+}
+
+func (node *radixNode[Endpoint]) lookup(
+	line RequestLine,
+	remaining string,
+	captures RequestLineParams,
+) (radixLeaf[Endpoint], RequestLineParams, bool) {
+	if remaining == "" {
+		if leaf, found, ok := node.lookupLeaf(line.Method, line, captures); ok {
+			return leaf, found, true
+		}
+		return node.lookupLeaf(AnyMethod, line, captures)
+	}
+	for _, child := range node.statics {
+		if child.prefix[0] != remaining[0] {
+			continue
+		}
+		if len(remaining) >= len(child.prefix) && remaining[:len(child.prefix)] == child.prefix {
+			leaf, found, ok := child.lookup(line, remaining[len(child.prefix):], captures)
+			if ok {
+				return leaf, found, true
+			}
+		}
+		break
+	}
+	var leaf radixLeaf[Endpoint]
+	if remaining[0] != '/' {
+		return leaf, captures, false
+	}
+	capture, newRemaining := takeUntilByte(remaining[1:], '/')
+	for _, param := range node.params {
+		if !matchesConstraint(param.constraint, capture) {
+			continue
+		}
+		captured := append(captures, RequestLineParam{param.name, capture})
+		leaf, found, ok := param.node.lookup(line, newRemaining, captured)
+		if ok {
+			return leaf, found, true
+		}
+	}
+	for _, catchAll := range node.catchAlls {
+		captured := append(captures, RequestLineParam{catchAll.name, remaining[1:]})
+		leaf, found, ok := catchAll.node.lookup(line, "", captured)
+		if ok {
+			return leaf, found, true
+		}
+	}
+	return leaf, captures, false
+}
+
+func (node *radixNode[Endpoint]) allow(line RequestLine, remaining string, allowed []string) []string {
+	if remaining == "" {
+		for _, method := range node.allowed {
+			if _, _, ok := node.lookupLeaf(method, line, nil); ok {
+				allowed = appendMissing(allowed, method)
+			}
+		}
+		return allowed
+	}
+	for _, child := range node.statics {
+		if child.prefix[0] != remaining[0] {
+			continue
+		}
+		if len(remaining) >= len(child.prefix) && remaining[:len(child.prefix)] == child.prefix {
+			allowed = child.allow(line, remaining[len(child.prefix):], allowed)
+		}
+		break
+	}
+	if remaining[0] != '/' {
+		return allowed
+	}
+	capture, newRemaining := takeUntilByte(remaining[1:], '/')
+	for _, param := range node.params {
+		if !matchesConstraint(param.constraint, capture) {
+			continue
+		}
+		allowed = param.node.allow(line, newRemaining, allowed)
+	}
+	for _, catchAll := range node.catchAlls {
+		allowed = catchAll.node.allow(line, "", allowed)
+	}
+	return allowed
+}
+
+func (compiler RadixCompiler[Endpoint]) Root(
+	missing Endpoint,
+) func(branches ...[]RadixRoute[Endpoint]) RequestLineRoot[Endpoint] {
+	return compiler.RootWithMethodNotAllowed(missing, missing)
+}
+
+func (compiler RadixCompiler[Endpoint]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(branches ...[]RadixRoute[Endpoint]) RequestLineRoot[Endpoint] {
+	return func(branches ...[]RadixRoute[Endpoint]) RequestLineRoot[Endpoint] {
+		root := &radixNode[Endpoint]{}
+		for _, route := range flatten(branches) {
+			root.insert(route)
+		}
+		return func(line RequestLine) RequestLineMatch[Endpoint] {
+			leaf, captures, ok := root.lookup(line, line.Path, nil)
+			if !ok {
+				allowed := root.allow(line, line.Path, nil)
+				if len(allowed) > 0 {
+					return RequestLineMatch[Endpoint]{Endpoint: methodNotAllowed, Params: map[string]string{}, Allowed: allowed}
+				}
+				return RequestLineMatch[Endpoint]{Endpoint: missing, Params: map[string]string{}}
+			}
+			params := make(map[string]string, len(captures))
+			for _, capture := range captures {
+				params[capture.Name] = capture.Value
+			}
+			return RequestLineMatch[Endpoint]{
+				Endpoint:   leaf.endpoint,
+				Params:     params,
+				Name:       leaf.name,
+				Middleware: leaf.middleware,
+				Meta:       leaf.meta,
+			}
+		}
+	}
+}
+
+func prefixRadixBranches[Endpoint any](
+	segment radixSegment,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+		return flattenThenMap(branches, func(route RadixRoute[Endpoint]) RadixRoute[Endpoint] {
+			return route.prefixedWith(segment)
+		})
+	}
+}
+
+func (compiler RadixCompiler[Endpoint]) Path(
+	prefix string,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return prefixRadixBranches[Endpoint](radixSegment{radixStatic, prefix, nil})
+}
+
+func (compiler RadixCompiler[Endpoint]) Param(
+	name string,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return prefixRadixBranches[Endpoint](radixSegment{radixParam, name, nil})
+}
+
+func (compiler RadixCompiler[Endpoint]) ConstrainedParam(
+	name string,
+	constraint ParamConstraint,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return prefixRadixBranches[Endpoint](radixSegment{radixParam, name, constraint})
+}
+
+func (compiler RadixCompiler[Endpoint]) CatchAll(
+	name string,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return prefixRadixBranches[Endpoint](radixSegment{radixCatchAll, name, nil})
+}
+
+func (compiler RadixCompiler[Endpoint]) Mount(
+	prefix string,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return mountPrefix[[]RadixRoute[Endpoint]](compiler, prefix)
+}
+
+func (compiler RadixCompiler[Endpoint]) Named(
+	name string,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+		return flattenThenMap(branches, func(route RadixRoute[Endpoint]) RadixRoute[Endpoint] {
+			return route.named(name)
+		})
+	}
+}
+
+func (compiler RadixCompiler[Endpoint]) Host(
+	pattern string,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	host := parseHostPattern(pattern)
+	return func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+		return flattenThenMap(branches, func(route RadixRoute[Endpoint]) RadixRoute[Endpoint] {
+			return route.hosted(host)
+		})
+	}
+}
+
+func (compiler RadixCompiler[Endpoint]) Where(
+	predicate RequestPredicate,
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+		return flattenThenMap(branches, func(route RadixRoute[Endpoint]) RadixRoute[Endpoint] {
+			return route.prefixedWithPredicate(predicate)
+		})
+	}
+}
+
+func (compiler RadixCompiler[Endpoint]) With(
+	middleware ...Middleware[Endpoint],
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+		return flattenThenMap(branches, func(route RadixRoute[Endpoint]) RadixRoute[Endpoint] {
+			return route.prefixedWithMiddleware(middleware)
+		})
+	}
+}
+
+func (compiler RadixCompiler[Endpoint]) Meta(
+	key string,
+	value interface{},
+) func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+	return func(branches ...[]RadixRoute[Endpoint]) []RadixRoute[Endpoint] {
+		return flattenThenMap(branches, func(route RadixRoute[Endpoint]) RadixRoute[Endpoint] {
+			return route.withMeta(key, value)
+		})
+	}
+}
+
+func radixMethod[Endpoint any](method string, endpoint Endpoint) []RadixRoute[Endpoint] {
+	return []RadixRoute[Endpoint]{{method: method, endpoint: endpoint}}
+}
+
+func (compiler RadixCompiler[Endpoint]) Method(name string, endpoint Endpoint) []RadixRoute[Endpoint] {
+	return radixMethod(name, endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Methods(names []string, endpoint Endpoint) []RadixRoute[Endpoint] {
+	routes := make([]RadixRoute[Endpoint], 0, len(names))
+	for _, name := range names {
+		routes = append(routes, radixMethod(name, endpoint)...)
+	}
+	return routes
+}
+
+// Any is looked up at a node only once no explicit method there matches, as
+// with RequestLineCompiler.
+func (compiler RadixCompiler[Endpoint]) Any(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return radixMethod(AnyMethod, endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Get(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("GET", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Post(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("POST", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Put(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("PUT", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Delete(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("DELETE", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Options(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("OPTIONS", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Patch(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("PATCH", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Head(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("HEAD", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Connect(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("CONNECT", endpoint)
+}
+
+func (compiler RadixCompiler[Endpoint]) Trace(endpoint Endpoint) []RadixRoute[Endpoint] {
+	return compiler.Method("TRACE", endpoint)
+}
@@ -0,0 +1,649 @@
+package http_routing
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRadixCompiler(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Path("/")(dsl.Get("IndexRender")),
+		dsl.Path("/log_in")(
+			dsl.Get("LogInRender"),
+			dsl.Post("LogInProcess"),
+		),
+		dsl.Path("/logs")(dsl.Get("LogsRender")),
+		dsl.Path("/exhaustive")(
+			dsl.Get("ExhaustiveGet"),
+			dsl.Post("ExhaustivePost"),
+			dsl.Put("ExhaustivePut"),
+			dsl.Delete("ExhaustiveDelete"),
+			dsl.Options("ExhaustiveOptions"),
+			dsl.Patch("ExhaustivePatch"),
+			dsl.Head("ExhaustiveHead"),
+			dsl.Connect("ExhaustiveConnect"),
+			dsl.Trace("ExhaustiveTrace"),
+		),
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+				dsl.Put("ApiUpdateUser"),
+				dsl.Delete("ApiDeleteUser"),
+			),
+			dsl.Path("/me")(dsl.Get("ApiFetchSelf")),
+		),
+		dsl.Path("/posts")(
+			dsl.Param("slug")(dsl.Get("ApiFetchPostBySlug")),
+			dsl.ConstrainedParam("post_id", IntConstraint())(
+				dsl.Get("ApiFetchPost"),
+				dsl.Put("ApiUpdatePost"),
+			),
+		),
+		dsl.Path("/static")(
+			dsl.CatchAll("file")(dsl.Get("StaticFile")),
+			dsl.Param("dir")(dsl.Get("StaticDir")),
+			dsl.Path("/index.html")(dsl.Get("StaticIndex")),
+		),
+		dsl.Path("/pre_match")(
+			dsl.Param("first")(
+				dsl.Param("second")(
+					dsl.Path("/post_match")(
+						dsl.Get("ParamMatch"),
+					),
+				),
+			),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "match missing route",
+			request: RequestLine{Method: "GET", Path: "/idk"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match missing route via terminal param",
+			request: RequestLine{Method: "GET", Path: "/pre_match"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match missing route via incomplete param",
+			request: RequestLine{Method: "GET", Path: "/pre_match/matches"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match multi param",
+			request: RequestLine{Method: "GET", Path: "/pre_match/a/b/post_match"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ParamMatch",
+				Params:   map[string]string{"first": "a", "second": "b"},
+			},
+		},
+		{
+			name:    "match index route",
+			request: RequestLine{Method: "GET", Path: "/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "IndexRender",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match split static prefix",
+			request: RequestLine{Method: "POST", Path: "/log_in"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "LogInProcess",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match sibling of split static prefix",
+			request: RequestLine{Method: "GET", Path: "/logs"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "LogsRender",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match path and trace",
+			request: RequestLine{Method: "TRACE", Path: "/exhaustive"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ExhaustiveTrace",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match catch all",
+			request: RequestLine{Method: "GET", Path: "/static/css/site.css"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticFile",
+				Params:   map[string]string{"file": "css/site.css"},
+			},
+		},
+		{
+			name:    "param takes precedence over earlier catch all",
+			request: RequestLine{Method: "GET", Path: "/static/css"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticDir",
+				Params:   map[string]string{"dir": "css"},
+			},
+		},
+		{
+			name:    "static takes precedence over earlier catch all",
+			request: RequestLine{Method: "GET", Path: "/static/index.html"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticIndex",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "constrained param takes precedence over earlier param",
+			request: RequestLine{Method: "GET", Path: "/posts/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPost",
+				Params:   map[string]string{"post_id": "1337"},
+			},
+		},
+		{
+			name:    "match constrained param falls through",
+			request: RequestLine{Method: "GET", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPostBySlug",
+				Params:   map[string]string{"slug": "latest"},
+			},
+		},
+		{
+			name:    "match allowed methods via constrained param",
+			request: RequestLine{Method: "PUT", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET"},
+			},
+		},
+		{
+			name:    "rest resource get",
+			request: RequestLine{Method: "GET", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchUser",
+				Params:   map[string]string{"user_id": "1337"},
+			},
+		},
+		{
+			name:    "static takes precedence over earlier param",
+			request: RequestLine{Method: "GET", Path: "/users/me"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchSelf",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "param matched after static backtracks",
+			request: RequestLine{Method: "DELETE", Path: "/users/me"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiDeleteUser",
+				Params:   map[string]string{"user_id": "me"},
+			},
+		},
+		{
+			name:    "param sharing a static prefix",
+			request: RequestLine{Method: "PUT", Path: "/users/meow"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiUpdateUser",
+				Params:   map[string]string{"user_id": "meow"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func makeBenchmarkRoutes[Branch any, Out any](dsl Compiler[string, Branch, Out]) Out {
+	resources := make([]Branch, 0, 250)
+	for i := 0; i < 250; i++ {
+		name := fmt.Sprintf("resource_%d", i)
+		resources = append(resources, dsl.Path("/"+name)(
+			dsl.Get("List"+name),
+			dsl.Post("Create"+name),
+			dsl.Param("id")(
+				dsl.Get("Fetch"+name),
+				dsl.Put("Update"+name),
+				dsl.Delete("Delete"+name),
+			),
+		))
+	}
+	return dsl.Root("Missing")(resources...)
+}
+
+func benchmarkRoutes(b *testing.B, routes RequestLineRoot[string]) {
+	requests := []RequestLine{
+		{Method: "GET", Path: "/resource_0"},
+		{Method: "PUT", Path: "/resource_125/1337"},
+		{Method: "DELETE", Path: "/resource_249/1337"},
+		{Method: "GET", Path: "/resource_250/1337"},
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		routes(requests[i%len(requests)])
+	}
+}
+
+func BenchmarkRequestLineCompiler(b *testing.B) {
+	benchmarkRoutes(b, makeBenchmarkRoutes(NewRequestLineCompiler[string]()))
+}
+
+func BenchmarkRadixCompiler(b *testing.B) {
+	benchmarkRoutes(b, makeBenchmarkRoutes(NewRadixCompiler[string]()))
+}
+
+func TestRadixCompilerMethodNotAllowed(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+				dsl.Delete("ApiDeleteUser"),
+			),
+			dsl.Path("/me")(dsl.Put("ApiUpdateSelf")),
+		),
+		dsl.Path("/users")(dsl.Get("ApiListUsers"), dsl.Post("ApiCreateUserAgain")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "missing path",
+			request: RequestLine{Method: "GET", Path: "/idk"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "method not allowed",
+			request: RequestLine{Method: "PATCH", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE"},
+			},
+		},
+		{
+			name:    "method not allowed across branches",
+			request: RequestLine{Method: "DELETE", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"POST", "GET"},
+			},
+		},
+		{
+			name:    "method not allowed across static and param",
+			request: RequestLine{Method: "POST", Path: "/users/me"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"PUT", "GET", "DELETE"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerNamedRoutes(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Named("users")(dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Named("users.fetch")(dsl.Get("ApiFetchUser")),
+			),
+		)),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "outer name",
+			request: RequestLine{Method: "POST", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiCreateUser",
+				Params:   map[string]string{},
+				Name:     "users",
+			},
+		},
+		{
+			name:    "inner name takes precedence",
+			request: RequestLine{Method: "GET", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchUser",
+				Params:   map[string]string{"user_id": "1337"},
+				Name:     "users.fetch",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerHost(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Host("api.example.com")(dsl.Path("/users")(dsl.Get("ApiListUsers"))),
+		dsl.Host("{tenant}.example.com")(dsl.Path("/users")(
+			dsl.Get("TenantListUsers"),
+			dsl.Param("user_id")(dsl.Get("TenantFetchUser")),
+		)),
+		dsl.Path("/users")(dsl.Post("CreateUser")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "static host",
+			request: RequestLine{Method: "GET", Host: "api.example.com:443", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiListUsers",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "host capture at the same path",
+			request: RequestLine{Method: "GET", Host: "acme.example.com", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "TenantListUsers",
+				Params:   map[string]string{"tenant": "acme"},
+			},
+		},
+		{
+			name:    "host capture with path param",
+			request: RequestLine{Method: "GET", Host: "acme.example.com", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "TenantFetchUser",
+				Params:   map[string]string{"tenant": "acme", "user_id": "1337"},
+			},
+		},
+		{
+			name:    "allowed methods respect host",
+			request: RequestLine{Method: "DELETE", Host: "www.example.org", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"POST"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerWhere(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Path("/users")(
+			dsl.Where(AcceptPredicate("application/vnd.v2+json"))(dsl.Get("ListUsersV2")),
+			dsl.Get("ListUsers"),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name: "predicate matches",
+			request: RequestLine{
+				Method: "GET",
+				Path:   "/users",
+				Header: http.Header{"Accept": {"application/vnd.v2+json"}},
+			},
+			expected: RequestLineMatch[string]{Endpoint: "ListUsersV2", Params: map[string]string{}},
+		},
+		{
+			name:     "fallback without predicates",
+			request:  RequestLine{Method: "GET", Path: "/users"},
+			expected: RequestLineMatch[string]{Endpoint: "ListUsers", Params: map[string]string{}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerWith(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.With(wrappingMiddleware("Log"))(dsl.Path("/users")(
+			dsl.With(wrappingMiddleware("Auth"))(dsl.Path("/me")(dsl.Get("ApiFetchSelf"))),
+			dsl.Param("user_id")(dsl.Get("ApiFetchUser")),
+		)),
+	)
+	var tests = []struct {
+		request  RequestLine
+		expected string
+	}{
+		{request: RequestLine{Method: "GET", Path: "/users/me"}, expected: "Log(Auth(ApiFetchSelf))"},
+		{request: RequestLine{Method: "GET", Path: "/users/1337"}, expected: "Log(ApiFetchUser)"},
+		{request: RequestLine{Method: "GET", Path: "/idk"}, expected: "Missing"},
+	}
+	for _, test := range tests {
+		t.Run(test.request.Path, func(t *testing.T) {
+			match := routes(test.request)
+			if result := Chain(match.Middleware, match.Endpoint); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerMeta(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Meta("owner", "identity")(dsl.Path("/users")(
+			dsl.Meta("owner", "accounts")(dsl.Path("/me")(dsl.Get("ApiFetchSelf"))),
+			dsl.Param("user_id")(dsl.Get("ApiFetchUser")),
+		)),
+	)
+	var tests = []struct {
+		request  RequestLine
+		expected map[string]interface{}
+	}{
+		{request: RequestLine{Method: "GET", Path: "/users/me"}, expected: map[string]interface{}{"owner": "accounts"}},
+		{request: RequestLine{Method: "GET", Path: "/users/1337"}, expected: map[string]interface{}{"owner": "identity"}},
+		{request: RequestLine{Method: "GET", Path: "/idk"}},
+	}
+	for _, test := range tests {
+		t.Run(test.request.Path, func(t *testing.T) {
+			if result := routes(test.request).Meta; !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerMount(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Mount("/orgs/{org_id}/billing")(makeBillingRoutes[[]RadixRoute[string]](dsl)),
+	)
+	result := routes(RequestLine{Method: "GET", Path: "/orgs/acme/billing/invoices/7"})
+	expected := RequestLineMatch[string]{
+		Endpoint: "FetchInvoice",
+		Params:   map[string]string{"org_id": "acme", "invoice_id": "7"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestRadixCompilerCustomMethods(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/files")(
+			dsl.Method("PROPFIND", "DavListFiles"),
+			dsl.Method("MKCOL", "DavMakeCollection"),
+			dsl.Post("UploadFile"),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:     "custom method",
+			request:  RequestLine{Method: "PROPFIND", Path: "/files"},
+			expected: RequestLineMatch[string]{Endpoint: "DavListFiles", Params: map[string]string{}},
+		},
+		{
+			name:     "standard method alongside custom ones",
+			request:  RequestLine{Method: "POST", Path: "/files"},
+			expected: RequestLineMatch[string]{Endpoint: "UploadFile", Params: map[string]string{}},
+		},
+		{
+			name:    "custom methods are allowed",
+			request: RequestLine{Method: "QUERY", Path: "/files"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"PROPFIND", "MKCOL", "POST"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := routes(test.request); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRadixCompilerAnyAndMethods(t *testing.T) {
+	dsl := NewRadixCompiler[string]()
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/proxy")(dsl.CatchAll("rest")(dsl.Any("Proxy"))),
+		dsl.Path("/proxy/health")(dsl.Get("Health")),
+		dsl.Path("/files")(
+			dsl.Methods([]string{"GET", "HEAD"}, "FetchFile"),
+			dsl.Post("UploadFile"),
+		),
+		dsl.Path("/ping")(dsl.Any("PingAny"), dsl.Get("Ping")),
+		dsl.Path("/status")(dsl.Any("StatusAny")),
+		dsl.CatchAll("path")(dsl.Get("StaticFile")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:     "any method",
+			request:  RequestLine{Method: "PROPFIND", Path: "/proxy/a/b"},
+			expected: RequestLineMatch[string]{Endpoint: "Proxy", Params: map[string]string{"rest": "a/b"}},
+		},
+		{
+			name:     "explicit method declared later takes precedence",
+			request:  RequestLine{Method: "GET", Path: "/proxy/health"},
+			expected: RequestLineMatch[string]{Endpoint: "Health", Params: map[string]string{}},
+		},
+		{
+			name:     "any method where the explicit one doesn't match",
+			request:  RequestLine{Method: "POST", Path: "/proxy/health"},
+			expected: RequestLineMatch[string]{Endpoint: "Proxy", Params: map[string]string{"rest": "health"}},
+		},
+		{
+			name:     "explicit method declared after any at the same path",
+			request:  RequestLine{Method: "GET", Path: "/ping"},
+			expected: RequestLineMatch[string]{Endpoint: "Ping", Params: map[string]string{}},
+		},
+		{
+			name:     "any alongside an explicit method",
+			request:  RequestLine{Method: "DELETE", Path: "/ping"},
+			expected: RequestLineMatch[string]{Endpoint: "PingAny", Params: map[string]string{}},
+		},
+		{
+			name:     "any before an explicit method at another path",
+			request:  RequestLine{Method: "GET", Path: "/status"},
+			expected: RequestLineMatch[string]{Endpoint: "StatusAny", Params: map[string]string{}},
+		},
+		{
+			name:     "explicit method at another path",
+			request:  RequestLine{Method: "GET", Path: "/robots.txt"},
+			expected: RequestLineMatch[string]{Endpoint: "StaticFile", Params: map[string]string{"path": "robots.txt"}},
+		},
+		{
+			name:     "one of several methods",
+			request:  RequestLine{Method: "HEAD", Path: "/files"},
+			expected: RequestLineMatch[string]{Endpoint: "FetchFile", Params: map[string]string{}},
+		},
+		{
+			name:    "none of several methods",
+			request: RequestLine{Method: "DELETE", Path: "/files"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "HEAD", "POST"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := routes(test.request); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
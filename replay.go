@@ -0,0 +1,223 @@
+package http_routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func parseRequestPredicate(spec string) (RequestPredicate, error) {
+	kind, argument, ok := strings.Cut(spec, "(")
+	if !ok || !strings.HasSuffix(argument, ")") {
+		return nil, fmt.Errorf("unknown request predicate %q", spec)
+	}
+	argument = argument[:len(argument)-1]
+	switch kind {
+	case "header", "query":
+		name, value, ok := strings.Cut(argument, "=")
+		if !ok {
+			return nil, fmt.Errorf("request predicate %q is missing a value", spec)
+		}
+		if kind == "header" {
+			return HeaderPredicate(name, value), nil
+		}
+		return QueryPredicate(name, value), nil
+	case "content-type":
+		return ContentTypePredicate(argument), nil
+	case "accept":
+		return AcceptPredicate(argument), nil
+	}
+	return nil, fmt.Errorf("unknown request predicate %q", spec)
+}
+
+func replayMethod[Endpoint any, Branch any, Out any](
+	compiler Compiler[Endpoint, Branch, Out],
+	method string,
+	endpoint Endpoint,
+) (Branch, error) {
+	if method == AnyMethod {
+		return compiler.Any(endpoint), nil
+	}
+	if !httpMethod(method).valid() {
+		var branch Branch
+		return branch, fmt.Errorf("%w %q", ErrUnknownMethod, method)
+	}
+	return compiler.Method(method, endpoint), nil
+}
+
+func replayRoute[Endpoint any, Branch any, Out any](
+	compiler Compiler[Endpoint, Branch, Out],
+	route RouteDescription[Endpoint],
+) (Branch, error) {
+	branch, err := replayMethod(compiler, route.Method, route.Endpoint)
+	if err != nil {
+		return branch, err
+	}
+	segments := route.Segments
+	if segments == nil {
+		segments, err = parsePath(route.Path, route.Constraints)
+		if err != nil {
+			return branch, err
+		}
+	}
+	if route.Name != "" {
+		branch = compiler.Named(route.Name)(branch)
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		branch = segmentCombinator[Branch](compiler, segments[i].radix())(branch)
+	}
+	keys := make([]string, 0, len(route.Meta))
+	for key := range route.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		branch = compiler.Meta(key, route.Meta[key])(branch)
+	}
+	if len(route.Middleware) > 0 {
+		branch = compiler.With(route.Middleware...)(branch)
+	}
+	for i := len(route.Predicates) - 1; i >= 0; i-- {
+		branch = compiler.Where(route.Predicates[i])(branch)
+	}
+	if route.Host != "" {
+		branch = compiler.Host(route.Host)(branch)
+	}
+	return branch, nil
+}
+
+// ReplayDescription feeds every described route, in order, into the compiler
+// as its own branch, so first-match compilers resolve them as they were
+// declared.
+func ReplayDescription[Endpoint any, Branch any, Out any](
+	description Description[Endpoint],
+	compiler Compiler[Endpoint, Branch, Out],
+) (Out, error) {
+	branches := make([]Branch, 0, len(description.Routes))
+	for i, route := range description.Routes {
+		branch, err := replayRoute(compiler, route)
+		if err != nil {
+			var out Out
+			return out, fmt.Errorf("route %d %s %s: %w", i, route.Method, route.Path, err)
+		}
+		branches = append(branches, branch)
+	}
+	if description.MethodNotAllowed != nil {
+		return compiler.RootWithMethodNotAllowed(description.Missing, *description.MethodNotAllowed)(branches...), nil
+	}
+	return compiler.Root(description.Missing)(branches...), nil
+}
+
+func ReplayDescriptionJSON[Endpoint any, Branch any, Out any](
+	data []byte,
+	compiler Compiler[Endpoint, Branch, Out],
+) (Out, error) {
+	var description Description[Endpoint]
+	if err := json.Unmarshal(data, &description); err != nil {
+		var out Out
+		return out, err
+	}
+	return ReplayDescription(description, compiler)
+}
+
+type descriptionJSON[Endpoint any] struct {
+	Missing          Endpoint                         `json:"missing"`
+	MethodNotAllowed *Endpoint                        `json:"methodNotAllowed,omitempty"`
+	Routes           []routeDescriptionJSON[Endpoint] `json:"routes"`
+}
+
+type routeDescriptionJSON[Endpoint any] struct {
+	Method     string                 `json:"method"`
+	Host       string                 `json:"host,omitempty"`
+	Path       string                 `json:"path"`
+	Endpoint   Endpoint               `json:"endpoint"`
+	Name       string                 `json:"name,omitempty"`
+	Predicates []string               `json:"predicates,omitempty"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+}
+
+// MarshalJSON keeps constraints inside the rendered path and host, and drops
+// middleware, which has no serialisable form.
+func (description Description[Endpoint]) MarshalJSON() ([]byte, error) {
+	out := descriptionJSON[Endpoint]{
+		Missing:          description.Missing,
+		MethodNotAllowed: description.MethodNotAllowed,
+		Routes:           make([]routeDescriptionJSON[Endpoint], 0, len(description.Routes)),
+	}
+	for _, route := range description.Routes {
+		var predicates []string
+		for _, predicate := range route.Predicates {
+			predicates = append(predicates, predicate.String())
+		}
+		out.Routes = append(out.Routes, routeDescriptionJSON[Endpoint]{
+			Method:     route.Method,
+			Host:       route.Host,
+			Path:       route.Path,
+			Endpoint:   route.Endpoint,
+			Name:       route.Name,
+			Predicates: predicates,
+			Meta:       route.Meta,
+		})
+	}
+	return json.Marshal(out)
+}
+
+func (description *Description[Endpoint]) UnmarshalJSON(data []byte) error {
+	var in descriptionJSON[Endpoint]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	routes := make([]RouteDescription[Endpoint], 0, len(in.Routes))
+	for i, route := range in.Routes {
+		described, err := unmarshalRouteDescription(route)
+		if err != nil {
+			return fmt.Errorf("route %d %s %s: %w", i, route.Method, route.Path, err)
+		}
+		routes = append(routes, described)
+	}
+	*description = Description[Endpoint]{in.Missing, in.MethodNotAllowed, routes}
+	return nil
+}
+
+func unmarshalRouteDescription[Endpoint any](route routeDescriptionJSON[Endpoint]) (RouteDescription[Endpoint], error) {
+	described := RouteDescription[Endpoint]{
+		Method:   route.Method,
+		Host:     route.Host,
+		Path:     route.Path,
+		Endpoint: route.Endpoint,
+		Meta:     route.Meta,
+		Name:     route.Name,
+	}
+	constraints := map[string]ParamConstraint{}
+	if route.Host != "" {
+		host, err := compileHostPattern(route.Host)
+		if err != nil {
+			return described, err
+		}
+		constraints = host.constraints()
+	}
+	segments, err := ParsePath(route.Path)
+	if err != nil {
+		return described, err
+	}
+	if len(segments) > 0 {
+		described.Segments = segments
+	}
+	for _, segment := range segments {
+		if segment.Constraint != nil {
+			constraints[segment.Value] = segment.Constraint
+		}
+	}
+	if len(constraints) > 0 {
+		described.Constraints = constraints
+	}
+	for _, spec := range route.Predicates {
+		predicate, err := parseRequestPredicate(spec)
+		if err != nil {
+			return described, err
+		}
+		described.Predicates = append(described.Predicates, predicate)
+	}
+	return described, nil
+}
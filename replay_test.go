@@ -0,0 +1,123 @@
+package http_routing
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func makeReplayRoutes[Branch any, Out any](dsl Compiler[string, Branch, Out]) Out {
+	return dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/")(dsl.Get("IndexRender")),
+		dsl.Meta("owner", "identity")(dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Where(AcceptPredicate("application/vnd.v2+json"))(dsl.Get("ApiListUsersV2")),
+			dsl.ConstrainedParam("user_id", IntConstraint())(
+				dsl.Named("users.fetch")(dsl.Get("ApiFetchUser")),
+				dsl.Path("/posts")(dsl.Param("post_id")(dsl.Path(".json")(dsl.Get("ApiFetchPostJson")))),
+			),
+		)),
+		dsl.Path("/files")(dsl.Methods([]string{"GET", "PROPFIND"}, "FetchFile")),
+		dsl.Path("/proxy")(dsl.CatchAll("rest")(dsl.Any("Proxy"))),
+		dsl.Host("{tenant:enum(acme,globex)}.example.com")(
+			dsl.Path("/static")(dsl.CatchAll("file")(dsl.Get("StaticFile"))),
+		),
+	)
+}
+
+func TestReplayDescription(t *testing.T) {
+	description := makeReplayRoutes(NewDescriptionCompiler[string]())
+	result, err := ReplayDescription(description, NewDescriptionCompiler[string]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, description) {
+		t.Errorf("got %+v, want %+v", result, description)
+	}
+}
+
+func TestReplayDescriptionIntoRequestLineCompiler(t *testing.T) {
+	description := makeReplayRoutes(NewDescriptionCompiler[string]())
+	routes, err := ReplayDescription(description, NewRequestLineCompiler[string]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := makeReplayRoutes(NewRequestLineCompiler[string]())
+	var tests = []RequestLine{
+		{Method: "GET", Path: "/"},
+		{Method: "GET", Path: "/users/1337"},
+		{Method: "GET", Path: "/users/abc"},
+		{Method: "GET", Path: "/users/1337/posts/7.json"},
+		{Method: "DELETE", Path: "/users"},
+		{Method: "PROPFIND", Path: "/files"},
+		{Method: "PATCH", Path: "/proxy/a/b"},
+		{Method: "GET", Host: "acme.example.com", Path: "/static/css/main.css"},
+		{Method: "GET", Host: "initech.example.com", Path: "/static/css/main.css"},
+	}
+	for _, test := range tests {
+		t.Run(test.Method+" "+test.Host+test.Path, func(t *testing.T) {
+			if result := routes(test); !reflect.DeepEqual(result, expected(test)) {
+				t.Errorf("got %+v, want %+v", result, expected(test))
+			}
+		})
+	}
+}
+
+func TestDescriptionJSON(t *testing.T) {
+	description := makeReplayRoutes(NewDescriptionCompiler[string]())
+	encoded, err := json.Marshal(description)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Description[string]
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, description) {
+		t.Errorf("got %+v, want %+v", decoded, description)
+	}
+}
+
+func TestReplayDescriptionJSON(t *testing.T) {
+	routes, err := ReplayDescriptionJSON([]byte(`{
+		"missing": "Missing",
+		"routes": [
+			{"method": "GET", "path": "/users/{user_id:int}", "endpoint": "ApiFetchUser", "name": "users.fetch"},
+			{"method": "GET", "path": "/users", "endpoint": "ApiListUsers", "predicates": ["query(beta=1)"]}
+		]
+	}`), NewRequestLineCompiler[string]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := routes(RequestLine{Method: "GET", Path: "/users/1337"})
+	expected := RequestLineMatch[string]{
+		Endpoint: "ApiFetchUser",
+		Params:   map[string]string{"user_id": "1337"},
+		Name:     "users.fetch",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestReplayDescriptionErrors(t *testing.T) {
+	var tests = []struct {
+		name string
+		data string
+	}{
+		{name: "unknown method", data: `{"routes": [{"method": "brew", "path": "/coffee"}]}`},
+		{name: "numeric method", data: `{"routes": [{"method": "123", "path": "/coffee"}]}`},
+		{name: "punctuation method", data: `{"routes": [{"method": "_", "path": "/coffee"}]}`},
+		{name: "unbalanced braces", data: `{"routes": [{"method": "GET", "path": "/users/{user_id"}]}`},
+		{name: "unknown constraint", data: `{"routes": [{"method": "GET", "path": "/users/{user_id:float}"}]}`},
+		{name: "unknown predicate", data: `{"routes": [{"method": "GET", "path": "/", "predicates": ["cookie(a=b)"]}]}`},
+		{name: "unknown host constraint", data: `{"routes": [{"method": "GET", "host": "{a:float}.example.com", "path": "/"}]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ReplayDescriptionJSON([]byte(test.data), NewRequestLineCompiler[string]()); err == nil {
+				t.Errorf("got no error, want one")
+			}
+		})
+	}
+}
@@ -1,56 +1,350 @@
 package http_routing
 
 import (
+	"net/http"
+	"net/url"
 	"strings"
 )
 
-type RequestLineCompiler[Endpoint any] struct{}
+type RequestLineCompiler[Endpoint any] struct {
+	options func(allowed []string) Endpoint
+}
 
 type RequestLine struct {
 	Method string
+	Host   string
 	Path   string
+	Header http.Header
+	Query  url.Values
 }
 
 type RequestLineMatch[Endpoint any] struct {
-	Endpoint Endpoint
-	Params   map[string]string
+	Endpoint   Endpoint
+	Params     map[string]string
+	Allowed    []string
+	Name       string
+	Middleware []Middleware[Endpoint]
+	Meta       map[string]interface{}
+}
+
+type RequestLineParam struct {
+	Name  string
+	Value string
+}
+
+type RequestLineParams []RequestLineParam
+
+func (params RequestLineParams) Get(name string) (string, bool) {
+	for _, param := range params {
+		if param.Name == name {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+func (params RequestLineParams) Map() map[string]string {
+	out := make(map[string]string, len(params))
+	for _, param := range params {
+		if _, ok := out[param.Name]; !ok {
+			out[param.Name] = param.Value
+		}
+	}
+	return out
+}
+
+type RequestLineMetaEntry struct {
+	Key   string
+	Value interface{}
+}
+
+type RequestLineMeta []RequestLineMetaEntry
+
+func (meta RequestLineMeta) Get(key string) (interface{}, bool) {
+	for i := len(meta) - 1; i >= 0; i-- {
+		if meta[i].Key == key {
+			return meta[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+func (meta RequestLineMeta) Map() map[string]interface{} {
+	out := make(map[string]interface{}, len(meta))
+	for _, entry := range meta {
+		out[entry.Key] = entry.Value
+	}
+	return out
+}
+
+type RequestLineResult[Endpoint any] struct {
+	Endpoint   Endpoint
+	Params     RequestLineParams
+	Allowed    []string
+	Name       string
+	Middleware []Middleware[Endpoint]
+	Meta       RequestLineMeta
+}
+
+type RequestLineBranch[Endpoint any] struct {
+	match    func(line RequestLine, result *RequestLineResult[Endpoint]) bool
+	allow    func(line RequestLine, allowed []string) []string
+	capacity requestLineCapacity
 }
 
-func (match *RequestLineMatch[Endpoint]) withParam(key, value string) *RequestLineMatch[Endpoint] {
-	newParams := map[string]string{}
-	for k, v := range match.Params {
-		newParams[k] = v
+type requestLineCapacity struct {
+	params     int
+	middleware int
+	meta       int
+	anyMethod  bool
+	// paramNames and hosts catch a host capture named like a path param,
+	// whichever of the two wraps the other.
+	paramNames []string
+	hosts      []hostPattern
+}
+
+func (capacity requestLineCapacity) withParamName(name string) requestLineCapacity {
+	for _, host := range capacity.hosts {
+		if host.capturesName(name) {
+			panic(hostParamCollision(host.pattern, name))
+		}
 	}
-	newParams[key] = value
-	return &RequestLineMatch[Endpoint]{match.Endpoint, newParams}
+	capacity.paramNames = appendMissing(capacity.paramNames, name)
+	return capacity.withParams(1)
+}
+
+func (capacity requestLineCapacity) withParams(count int) requestLineCapacity {
+	capacity.params += count
+	return capacity
+}
+
+func (capacity requestLineCapacity) withMiddleware(count int) requestLineCapacity {
+	capacity.middleware += count
+	return capacity
+}
+
+func (capacity requestLineCapacity) withMeta(count int) requestLineCapacity {
+	capacity.meta += count
+	return capacity
 }
 
-type RequestLineBranch[Endpoint any] func(method string, remaining string) *RequestLineMatch[Endpoint]
 type RequestLineRoot[Endpoint any] func(line RequestLine) RequestLineMatch[Endpoint]
 
+type RequestLineMatcher[Endpoint any] struct {
+	missing          Endpoint
+	methodNotAllowed Endpoint
+	options          func(allowed []string) Endpoint
+	branches         []RequestLineBranch[Endpoint]
+	capacity         requestLineCapacity
+}
+
+type RequestLineMatcherCompiler[Endpoint any] struct {
+	RequestLineCompiler[Endpoint]
+}
+
 func NewRequestLineCompiler[Endpoint any]() Compiler[Endpoint, RequestLineBranch[Endpoint], RequestLineRoot[Endpoint]] {
 	return RequestLineCompiler[Endpoint]{}
 }
 
-func applyBranch[Endpoint any](
-	method string,
-	remaining string,
-) func(branch RequestLineBranch[Endpoint]) *RequestLineMatch[Endpoint] {
-	return func(branch RequestLineBranch[Endpoint]) *RequestLineMatch[Endpoint] {
-		return branch(method, remaining)
+func NewRequestLineCompilerWithAutomaticMethods[Endpoint any](
+	options func(allowed []string) Endpoint,
+) Compiler[Endpoint, RequestLineBranch[Endpoint], RequestLineRoot[Endpoint]] {
+	return RequestLineCompiler[Endpoint]{options}
+}
+
+func NewRequestLineMatcherCompiler[Endpoint any]() Compiler[
+	Endpoint,
+	RequestLineBranch[Endpoint],
+	RequestLineMatcher[Endpoint],
+] {
+	return RequestLineMatcherCompiler[Endpoint]{}
+}
+
+func NewRequestLineMatcherCompilerWithAutomaticMethods[Endpoint any](
+	options func(allowed []string) Endpoint,
+) Compiler[
+	Endpoint,
+	RequestLineBranch[Endpoint],
+	RequestLineMatcher[Endpoint],
+] {
+	return RequestLineMatcherCompiler[Endpoint]{RequestLineCompiler[Endpoint]{options}}
+}
+
+func branchesCapacity[Endpoint any](branches []RequestLineBranch[Endpoint]) requestLineCapacity {
+	capacity := requestLineCapacity{}
+	for _, branch := range branches {
+		if branch.capacity.params > capacity.params {
+			capacity.params = branch.capacity.params
+		}
+		if branch.capacity.middleware > capacity.middleware {
+			capacity.middleware = branch.capacity.middleware
+		}
+		if branch.capacity.meta > capacity.meta {
+			capacity.meta = branch.capacity.meta
+		}
+		capacity.anyMethod = capacity.anyMethod || branch.capacity.anyMethod
+		for _, name := range branch.capacity.paramNames {
+			capacity.paramNames = appendMissing(capacity.paramNames, name)
+		}
+		capacity.hosts = append(capacity.hosts, branch.capacity.hosts...)
+	}
+	return capacity
+}
+
+func matchBranches[Endpoint any](
+	branches []RequestLineBranch[Endpoint],
+	line RequestLine,
+	result *RequestLineResult[Endpoint],
+) bool {
+	for _, branch := range branches {
+		if branch.match(line, result) {
+			return true
+		}
+	}
+	return false
+}
+
+func allowBranches[Endpoint any](
+	branches []RequestLineBranch[Endpoint],
+	line RequestLine,
+	allowed []string,
+) []string {
+	for _, branch := range branches {
+		allowed = branch.allow(line, allowed)
+	}
+	return allowed
+}
+
+func newRequestLineMatcher[Endpoint any](
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+	options func(allowed []string) Endpoint,
+	branches []RequestLineBranch[Endpoint],
+) RequestLineMatcher[Endpoint] {
+	return RequestLineMatcher[Endpoint]{missing, methodNotAllowed, options, branches, branchesCapacity(branches)}
+}
+
+func (matcher RequestLineMatcher[Endpoint]) NewResult() RequestLineResult[Endpoint] {
+	result := RequestLineResult[Endpoint]{Params: make(RequestLineParams, 0, matcher.capacity.params)}
+	if matcher.capacity.middleware > 0 {
+		result.Middleware = make([]Middleware[Endpoint], 0, matcher.capacity.middleware)
+	}
+	if matcher.capacity.meta > 0 {
+		result.Meta = make(RequestLineMeta, 0, matcher.capacity.meta)
+	}
+	return result
+}
+
+func (matcher RequestLineMatcher[Endpoint]) Match(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+	result.Params = result.Params[:0]
+	result.Allowed = result.Allowed[:0]
+	result.Name = ""
+	result.Middleware = result.Middleware[:0]
+	result.Meta = result.Meta[:0]
+	if matchBranches(matcher.branches, line, result) {
+		return true
+	}
+	automatic := matcher.options != nil
+	if automatic && line.Method == "HEAD" && matchBranches(matcher.branches, line.withMethod("GET"), result) {
+		return true
+	}
+	if matcher.capacity.anyMethod && matchAnyMethod(automatic, matcher.branches, line, result) {
+		return true
+	}
+	result.Allowed = allowBranches(matcher.branches, line, result.Allowed)
+	if len(result.Allowed) == 0 {
+		result.Endpoint = matcher.missing
+		return false
+	}
+	if !automatic {
+		result.Endpoint = matcher.methodNotAllowed
+		return false
+	}
+	result.Allowed = withAutomaticMethods(result.Allowed)
+	if line.Method == "OPTIONS" {
+		result.Endpoint = matcher.options(result.Allowed)
+		return true
+	}
+	result.Endpoint = matcher.methodNotAllowed
+	return false
+}
+
+func (line RequestLine) withMethod(method string) RequestLine {
+	line.Method = method
+	return line
+}
+
+func (line RequestLine) withPath(path string) RequestLine {
+	line.Path = path
+	return line
+}
+
+// matchAnyMethod runs at each node with Any leaves below it that the path is
+// used up at, once its explicit methods have failed: automatic HEAD first, then the node's Any leaves. Any so
+// yields only to methods declared alongside it, not to routes elsewhere.
+func matchAnyMethod[Endpoint any](
+	automatic bool,
+	branches []RequestLineBranch[Endpoint],
+	line RequestLine,
+	result *RequestLineResult[Endpoint],
+) bool {
+	if line.Path != "" || line.Method == AnyMethod {
+		return false
+	}
+	if automatic && line.Method == "HEAD" && matchBranches(branches, line.withMethod("GET"), result) {
+		return true
+	}
+	return matchBranches(branches, line.withMethod(AnyMethod), result)
+}
+
+func withAutomaticMethods(allowed []string) []string {
+	for _, method := range allowed {
+		if method == "GET" {
+			allowed = appendMissing(allowed, "HEAD")
+			break
+		}
+	}
+	return appendMissing(allowed, "OPTIONS")
+}
+
+func (compiler RequestLineMatcherCompiler[Endpoint]) Root(
+	missing Endpoint,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineMatcher[Endpoint] {
+	return compiler.RootWithMethodNotAllowed(missing, missing)
+}
+
+func (compiler RequestLineMatcherCompiler[Endpoint]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineMatcher[Endpoint] {
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineMatcher[Endpoint] {
+		return newRequestLineMatcher(missing, methodNotAllowed, compiler.options, branches)
 	}
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Root(
 	missing Endpoint,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineRoot[Endpoint] {
+	return compiler.RootWithMethodNotAllowed(missing, missing)
+}
+
+func (compiler RequestLineCompiler[Endpoint]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
 ) func(branches ...RequestLineBranch[Endpoint]) RequestLineRoot[Endpoint] {
 	return func(branches ...RequestLineBranch[Endpoint]) RequestLineRoot[Endpoint] {
+		matcher := newRequestLineMatcher(missing, methodNotAllowed, compiler.options, branches)
 		return func(line RequestLine) RequestLineMatch[Endpoint] {
-			match := mapFind(branches, applyBranch[Endpoint](line.Method, line.Path))
-			if match == nil {
-				return RequestLineMatch[Endpoint]{missing, map[string]string{}}
+			result := matcher.NewResult()
+			matcher.Match(line, &result)
+			match := RequestLineMatch[Endpoint]{result.Endpoint, result.Params.Map(), result.Allowed, result.Name, nil, nil}
+			if len(result.Middleware) > 0 {
+				match.Middleware = result.Middleware
+			}
+			if len(result.Meta) > 0 {
+				match.Meta = result.Meta.Map()
 			}
-			return *match
+			return match
 		}
 	}
 }
@@ -59,78 +353,308 @@ func (compiler RequestLineCompiler[Endpoint]) Path(
 	prefix string,
 ) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
 	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
-		return func(method string, remaining string) *RequestLineMatch[Endpoint] {
-			if !strings.HasPrefix(remaining, prefix) {
-				return nil
+		anyMethod := branchesCapacity(branches).anyMethod
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			if !strings.HasPrefix(line.Path, prefix) {
+				return false
 			}
-			newRemaining := remaining[len(prefix):]
-			return mapFind(branches, applyBranch[Endpoint](method, newRemaining))
+			remaining := line.withPath(line.Path[len(prefix):])
+			return matchBranches(branches, remaining, result) ||
+				(anyMethod && matchAnyMethod(compiler.options != nil, branches, remaining, result))
 		}
+		allow := func(line RequestLine, allowed []string) []string {
+			if !strings.HasPrefix(line.Path, prefix) {
+				return allowed
+			}
+			return allowBranches(branches, line.withPath(line.Path[len(prefix):]), allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches)}
+	}
+}
+
+func makeParamMatcher[Endpoint any](
+	automatic bool,
+	name string,
+	constraint ParamConstraint,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+		anyMethod := branchesCapacity(branches).anyMethod
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			if !strings.HasPrefix(line.Path, "/") {
+				return false
+			}
+			capture, newRemaining := takeUntilByte(line.Path[1:], '/')
+			if !matchesConstraint(constraint, capture) {
+				return false
+			}
+			length := len(result.Params)
+			result.Params = append(result.Params, RequestLineParam{name, capture})
+			remaining := line.withPath(newRemaining)
+			if matchBranches(branches, remaining, result) || (anyMethod && matchAnyMethod(automatic, branches, remaining, result)) {
+				return true
+			}
+			result.Params = result.Params[:length]
+			return false
+		}
+		allow := func(line RequestLine, allowed []string) []string {
+			if !strings.HasPrefix(line.Path, "/") {
+				return allowed
+			}
+			capture, newRemaining := takeUntilByte(line.Path[1:], '/')
+			if !matchesConstraint(constraint, capture) {
+				return allowed
+			}
+			return allowBranches(branches, line.withPath(newRemaining), allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches).withParamName(name)}
 	}
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Param(
 	name string,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return makeParamMatcher[Endpoint](compiler.options != nil, name, nil)
+}
+
+func (compiler RequestLineCompiler[Endpoint]) ConstrainedParam(
+	name string,
+	constraint ParamConstraint,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return makeParamMatcher[Endpoint](compiler.options != nil, name, constraint)
+}
+
+func (compiler RequestLineCompiler[Endpoint]) CatchAll(
+	name string,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+		anyMethod := branchesCapacity(branches).anyMethod
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			if !strings.HasPrefix(line.Path, "/") {
+				return false
+			}
+			length := len(result.Params)
+			result.Params = append(result.Params, RequestLineParam{name, line.Path[1:]})
+			remaining := line.withPath("")
+			if matchBranches(branches, remaining, result) ||
+				(anyMethod && matchAnyMethod(compiler.options != nil, branches, remaining, result)) {
+				return true
+			}
+			result.Params = result.Params[:length]
+			return false
+		}
+		allow := func(line RequestLine, allowed []string) []string {
+			if !strings.HasPrefix(line.Path, "/") {
+				return allowed
+			}
+			return allowBranches(branches, line.withPath(""), allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches).withParamName(name)}
+	}
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Mount(
+	prefix string,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return mountPrefix[RequestLineBranch[Endpoint]](compiler, prefix)
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Named(
+	name string,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			if !matchBranches(branches, line, result) {
+				return false
+			}
+			if result.Name == "" {
+				result.Name = name
+			}
+			return true
+		}
+		allow := func(line RequestLine, allowed []string) []string {
+			return allowBranches(branches, line, allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches)}
+	}
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Host(
+	pattern string,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	host := parseHostPattern(pattern)
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+		capacity := branchesCapacity(branches)
+		if len(capacity.hosts) > 0 {
+			panic(nestedHost(pattern))
+		}
+		for _, name := range capacity.paramNames {
+			if host.capturesName(name) {
+				panic(hostParamCollision(pattern, name))
+			}
+		}
+		capacity.hosts = []hostPattern{host}
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			length := len(result.Params)
+			params, ok := host.match(line.Host, result.Params)
+			if !ok {
+				return false
+			}
+			result.Params = params
+			if matchBranches(branches, line, result) {
+				return true
+			}
+			result.Params = result.Params[:length]
+			return false
+		}
+		allow := func(line RequestLine, allowed []string) []string {
+			if _, ok := host.match(line.Host, nil); !ok {
+				return allowed
+			}
+			return allowBranches(branches, line, allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, capacity.withParams(host.captures())}
+	}
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Where(
+	predicate RequestPredicate,
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			return predicate.Matches(line) && matchBranches(branches, line, result)
+		}
+		allow := func(line RequestLine, allowed []string) []string {
+			if !predicate.Matches(line) {
+				return allowed
+			}
+			return allowBranches(branches, line, allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches)}
+	}
+}
+
+func (compiler RequestLineCompiler[Endpoint]) With(
+	middleware ...Middleware[Endpoint],
 ) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
 	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
-		return func(method string, remaining string) *RequestLineMatch[Endpoint] {
-			if !strings.HasPrefix(remaining, "/") {
-				return nil
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			length := len(result.Middleware)
+			result.Middleware = append(result.Middleware, middleware...)
+			if matchBranches(branches, line, result) {
+				return true
 			}
-			capture, newRemaining := takeUntilByte(remaining[1:], '/')
-			match := mapFind(branches, applyBranch[Endpoint](method, newRemaining))
-			if match == nil {
-				return nil
+			result.Middleware = result.Middleware[:length]
+			return false
+		}
+		allow := func(line RequestLine, allowed []string) []string {
+			return allowBranches(branches, line, allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches).withMiddleware(len(middleware))}
+	}
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Meta(
+	key string,
+	value interface{},
+) func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+	return func(branches ...RequestLineBranch[Endpoint]) RequestLineBranch[Endpoint] {
+		match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+			length := len(result.Meta)
+			result.Meta = append(result.Meta, RequestLineMetaEntry{key, value})
+			if matchBranches(branches, line, result) {
+				return true
 			}
-			return match.withParam(name, capture)
+			result.Meta = result.Meta[:length]
+			return false
 		}
+		allow := func(line RequestLine, allowed []string) []string {
+			return allowBranches(branches, line, allowed)
+		}
+		return RequestLineBranch[Endpoint]{match, allow, branchesCapacity(branches).withMeta(1)}
 	}
 }
 
-func makeMethodMatcher[Endpoint any](target string, endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return func(method string, remaining string) *RequestLineMatch[Endpoint] {
-		if remaining != "" {
-			return nil
+func makeMethodMatcher[Endpoint any](targets []string, endpoint Endpoint) RequestLineBranch[Endpoint] {
+	match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+		if line.Path != "" {
+			return false
 		}
-		if method != target {
-			return nil
+		for _, target := range targets {
+			if line.Method == target {
+				result.Endpoint = endpoint
+				return true
+			}
 		}
-		return &RequestLineMatch[Endpoint]{endpoint, map[string]string{}}
+		return false
+	}
+	allow := func(line RequestLine, allowed []string) []string {
+		if line.Path != "" {
+			return allowed
+		}
+		for _, target := range targets {
+			allowed = appendMissing(allowed, target)
+		}
+		return allowed
+	}
+	return RequestLineBranch[Endpoint]{match, allow, requestLineCapacity{}}
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Method(name string, endpoint Endpoint) RequestLineBranch[Endpoint] {
+	return makeMethodMatcher([]string{name}, endpoint)
+}
+
+func (compiler RequestLineCompiler[Endpoint]) Methods(names []string, endpoint Endpoint) RequestLineBranch[Endpoint] {
+	return makeMethodMatcher(names, endpoint)
+}
+
+// Any never adds to Allowed: wherever it is reachable it matches, so there is
+// no method left to refuse.
+func (compiler RequestLineCompiler[Endpoint]) Any(endpoint Endpoint) RequestLineBranch[Endpoint] {
+	match := func(line RequestLine, result *RequestLineResult[Endpoint]) bool {
+		if line.Path != "" || line.Method != AnyMethod {
+			return false
+		}
+		result.Endpoint = endpoint
+		return true
+	}
+	allow := func(line RequestLine, allowed []string) []string {
+		return allowed
 	}
+	return RequestLineBranch[Endpoint]{match, allow, requestLineCapacity{anyMethod: true}}
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Get(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("GET", endpoint)
+	return compiler.Method("GET", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Post(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("POST", endpoint)
+	return compiler.Method("POST", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Put(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("PUT", endpoint)
+	return compiler.Method("PUT", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Delete(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("DELETE", endpoint)
+	return compiler.Method("DELETE", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Options(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("OPTIONS", endpoint)
+	return compiler.Method("OPTIONS", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Patch(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("PATCH", endpoint)
+	return compiler.Method("PATCH", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Head(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("HEAD", endpoint)
+	return compiler.Method("HEAD", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Connect(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("CONNECT", endpoint)
+	return compiler.Method("CONNECT", endpoint)
 }
 
 func (compiler RequestLineCompiler[Endpoint]) Trace(endpoint Endpoint) RequestLineBranch[Endpoint] {
-	return makeMethodMatcher("TRACE", endpoint)
+	return compiler.Method("TRACE", endpoint)
 }
@@ -1,7 +1,10 @@
 package http_routing
 
 import (
+	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +48,14 @@ func TestRequestLineCompiler(t *testing.T) {
 				),
 			),
 		),
+		dsl.Path("/static")(dsl.CatchAll("file")(dsl.Get("StaticFile"))),
+		dsl.Path("/posts")(
+			dsl.ConstrainedParam("post_id", IntConstraint())(
+				dsl.Get("ApiFetchPost"),
+				dsl.Put("ApiUpdatePost"),
+			),
+			dsl.Param("slug")(dsl.Get("ApiFetchPostBySlug")),
+		),
 	)
 	var tests = []struct {
 		name     string
@@ -163,6 +174,55 @@ func TestRequestLineCompiler(t *testing.T) {
 				Params:   map[string]string{},
 			},
 		},
+		{
+			name:    "match catch all",
+			request: RequestLine{Method: "GET", Path: "/static/css/site.css"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticFile",
+				Params:   map[string]string{"file": "css/site.css"},
+			},
+		},
+		{
+			name:    "match empty catch all",
+			request: RequestLine{Method: "GET", Path: "/static/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "StaticFile",
+				Params:   map[string]string{"file": ""},
+			},
+		},
+		{
+			name:    "match missing route via catch all prefix",
+			request: RequestLine{Method: "GET", Path: "/static"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "match constrained param",
+			request: RequestLine{Method: "GET", Path: "/posts/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPost",
+				Params:   map[string]string{"post_id": "1337"},
+			},
+		},
+		{
+			name:    "match constrained param falls through",
+			request: RequestLine{Method: "GET", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchPostBySlug",
+				Params:   map[string]string{"slug": "latest"},
+			},
+		},
+		{
+			name:    "match allowed methods via constrained param",
+			request: RequestLine{Method: "PUT", Path: "/posts/latest"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET"},
+			},
+		},
 		{
 			name:    "rest resource create",
 			request: RequestLine{Method: "POST", Path: "/users"},
@@ -205,3 +265,895 @@ func TestRequestLineCompiler(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestLineMatcherCompiler(t *testing.T) {
+	dsl := NewRequestLineMatcherCompiler[string]()
+	matcher := dsl.Root("Missing")(
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+			),
+		),
+		dsl.Path("/pre_match")(
+			dsl.Param("first")(
+				dsl.Param("second")(
+					dsl.Path("/post_match")(
+						dsl.Get("ParamMatch"),
+					),
+				),
+				dsl.Path("/other")(dsl.Get("OtherMatch")),
+			),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		matched  bool
+		expected RequestLineResult[string]
+	}{
+		{
+			name:    "match missing route",
+			request: RequestLine{Method: "GET", Path: "/idk"},
+			matched: false,
+			expected: RequestLineResult[string]{
+				Endpoint: "Missing",
+				Params:   RequestLineParams{},
+			},
+		},
+		{
+			name:    "match without params",
+			request: RequestLine{Method: "POST", Path: "/users"},
+			matched: true,
+			expected: RequestLineResult[string]{
+				Endpoint: "ApiCreateUser",
+				Params:   RequestLineParams{},
+			},
+		},
+		{
+			name:    "match multi param in order",
+			request: RequestLine{Method: "GET", Path: "/pre_match/a/b/post_match"},
+			matched: true,
+			expected: RequestLineResult[string]{
+				Endpoint: "ParamMatch",
+				Params:   RequestLineParams{{"first", "a"}, {"second", "b"}},
+			},
+		},
+		{
+			name:    "match discards params of failed branches",
+			request: RequestLine{Method: "GET", Path: "/pre_match/a/other"},
+			matched: true,
+			expected: RequestLineResult[string]{
+				Endpoint: "OtherMatch",
+				Params:   RequestLineParams{{"first", "a"}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := matcher.NewResult()
+			matched := matcher.Match(test.request, &result)
+			if matched != test.matched {
+				t.Errorf("got matched %v, want %v", matched, test.matched)
+			}
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineMatcherResultCapacity(t *testing.T) {
+	dsl := NewRequestLineMatcherCompiler[string]()
+	matcher := dsl.Root("Missing")(
+		dsl.Param("a")(dsl.Get("One")),
+		dsl.Param("a")(dsl.Param("b")(dsl.Param("c")(dsl.Get("Three")))),
+		dsl.Path("/x")(dsl.Param("a")(dsl.Param("b")(dsl.Get("Two")))),
+	)
+	if capacity := cap(matcher.NewResult().Params); capacity != 3 {
+		t.Errorf("got capacity %d, want 3", capacity)
+	}
+}
+
+func TestRequestLineMatcherAllocations(t *testing.T) {
+	dsl := NewRequestLineMatcherCompiler[string]()
+	matcher := dsl.Root("Missing")(
+		dsl.Path("/users")(
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+				dsl.Path("/posts")(dsl.Param("post_id")(
+					dsl.Get("ApiFetchPost"),
+				)),
+			),
+		),
+	)
+	var tests = []struct {
+		name    string
+		request RequestLine
+	}{
+		{name: "missing", request: RequestLine{Method: "GET", Path: "/idk"}},
+		{name: "single param", request: RequestLine{Method: "GET", Path: "/users/1337"}},
+		{name: "nested params", request: RequestLine{Method: "GET", Path: "/users/1337/posts/42"}},
+		{name: "backtracked params", request: RequestLine{Method: "GET", Path: "/users/1337/posts/42/idk"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := matcher.NewResult()
+			allocs := testing.AllocsPerRun(100, func() {
+				matcher.Match(test.request, &result)
+			})
+			if allocs != 0 {
+				t.Errorf("got %v allocations, want 0", allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkRequestLineMatcherCompiler(b *testing.B) {
+	matcher := makeBenchmarkRoutes(NewRequestLineMatcherCompiler[string]())
+	result := matcher.NewResult()
+	request := RequestLine{Method: "DELETE", Path: "/resource_249/1337"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.Match(request, &result)
+	}
+}
+
+func TestRequestLineCompilerMethodNotAllowed(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	branches := []RequestLineBranch[string]{
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+				dsl.Delete("ApiDeleteUser"),
+			),
+			dsl.Path("/me")(dsl.Put("ApiUpdateSelf")),
+		),
+		dsl.Path("/users")(dsl.Get("ApiListUsers"), dsl.Post("ApiCreateUserAgain")),
+	}
+	var tests = []struct {
+		name     string
+		routes   RequestLineRoot[string]
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "missing path",
+			routes:  dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(branches...),
+			request: RequestLine{Method: "GET", Path: "/idk"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "method not allowed",
+			routes:  dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(branches...),
+			request: RequestLine{Method: "PATCH", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE"},
+			},
+		},
+		{
+			name:    "method not allowed across branches",
+			routes:  dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(branches...),
+			request: RequestLine{Method: "DELETE", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"POST", "GET"},
+			},
+		},
+		{
+			name:    "method not allowed across static and param",
+			routes:  dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(branches...),
+			request: RequestLine{Method: "POST", Path: "/users/me"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE", "PUT"},
+			},
+		},
+		{
+			name:    "method not allowed defaults to missing",
+			routes:  dsl.Root("Missing")(branches...),
+			request: RequestLine{Method: "PATCH", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func describingOptions(allowed []string) string {
+	return "Options " + strings.Join(allowed, ",")
+}
+
+func TestRequestLineCompilerWithAutomaticMethods(t *testing.T) {
+	dsl := NewRequestLineCompilerWithAutomaticMethods(describingOptions)
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Get("ApiFetchUser"),
+				dsl.Delete("ApiDeleteUser"),
+			),
+		),
+		dsl.Path("/explicit")(
+			dsl.Get("ExplicitGet"),
+			dsl.Head("ExplicitHead"),
+			dsl.Options("ExplicitOptions"),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "missing path",
+			request: RequestLine{Method: "OPTIONS", Path: "/idk"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "head answered by get",
+			request: RequestLine{Method: "HEAD", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiFetchUser",
+				Params:   map[string]string{"user_id": "1337"},
+			},
+		},
+		{
+			name:    "head without get",
+			request: RequestLine{Method: "HEAD", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"POST", "OPTIONS"},
+			},
+		},
+		{
+			name:    "explicit head",
+			request: RequestLine{Method: "HEAD", Path: "/explicit"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ExplicitHead",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "synthesized options",
+			request: RequestLine{Method: "OPTIONS", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Options GET,DELETE,HEAD,OPTIONS",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE", "HEAD", "OPTIONS"},
+			},
+		},
+		{
+			name:    "explicit options",
+			request: RequestLine{Method: "OPTIONS", Path: "/explicit"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ExplicitOptions",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "method not allowed includes automatic methods",
+			request: RequestLine{Method: "PUT", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE", "HEAD", "OPTIONS"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineCompilerNamedRoutes(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Named("users")(dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Named("users.fetch")(dsl.Get("ApiFetchUser")),
+				dsl.Named("users.delete")(dsl.Delete("ApiDeleteUser")),
+			),
+		)),
+		dsl.Path("/")(dsl.Get("IndexRender")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "unnamed route",
+			request: RequestLine{Method: "GET", Path: "/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "IndexRender",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "outer name",
+			request: RequestLine{Method: "POST", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiCreateUser",
+				Params:   map[string]string{},
+				Name:     "users",
+			},
+		},
+		{
+			name:    "inner name takes precedence",
+			request: RequestLine{Method: "DELETE", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiDeleteUser",
+				Params:   map[string]string{"user_id": "1337"},
+				Name:     "users.delete",
+			},
+		},
+		{
+			name:    "missing route is unnamed",
+			request: RequestLine{Method: "PUT", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "DELETE"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineCompilerHost(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Host("api.example.com")(dsl.Path("/users")(dsl.Get("ApiListUsers"))),
+		dsl.Host("{tenant}.example.com")(
+			dsl.Path("/users")(dsl.Param("user_id")(dsl.Get("TenantFetchUser"))),
+		),
+		dsl.Host("{tenant:int}.example.com")(dsl.Path("/")(dsl.Get("NumericTenantIndex"))),
+		dsl.Path("/users")(dsl.Post("CreateUser")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "static host",
+			request: RequestLine{Method: "GET", Host: "api.example.com", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiListUsers",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "host ignores case, port and trailing dot",
+			request: RequestLine{Method: "GET", Host: "API.Example.com.:8080", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ApiListUsers",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "host capture",
+			request: RequestLine{Method: "GET", Host: "acme.example.com", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "TenantFetchUser",
+				Params:   map[string]string{"tenant": "acme", "user_id": "1337"},
+			},
+		},
+		{
+			name:    "host capture spans a single label",
+			request: RequestLine{Method: "GET", Host: "a.b.example.com", Path: "/users/1337"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "constrained host capture",
+			request: RequestLine{Method: "GET", Host: "42.example.com", Path: "/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NumericTenantIndex",
+				Params:   map[string]string{"tenant": "42"},
+			},
+		},
+		{
+			name:    "constrained host capture mismatch",
+			request: RequestLine{Method: "GET", Host: "acme.example.com", Path: "/"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "allowed methods respect host",
+			request: RequestLine{Method: "DELETE", Host: "api.example.com", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "POST"},
+			},
+		},
+		{
+			name:    "routes without host match any host",
+			request: RequestLine{Method: "POST", Host: "www.example.org", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "CreateUser",
+				Params:   map[string]string{},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineCompilerWhere(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Path("/users")(
+			dsl.Where(AcceptPredicate("application/vnd.v2+json"))(dsl.Get("ListUsersV2")),
+			dsl.Where(QueryPredicate("beta", "1"))(dsl.Get("ListUsersBeta")),
+			dsl.Get("ListUsers"),
+			dsl.Where(ContentTypePredicate("application/json"))(dsl.Post("CreateUserJson")),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name: "accept",
+			request: RequestLine{
+				Method: "GET",
+				Path:   "/users",
+				Header: http.Header{"Accept": {"application/vnd.v2+json"}},
+			},
+			expected: RequestLineMatch[string]{Endpoint: "ListUsersV2", Params: map[string]string{}},
+		},
+		{
+			name:     "query",
+			request:  RequestLine{Method: "GET", Path: "/users", Query: url.Values{"beta": {"1"}}},
+			expected: RequestLineMatch[string]{Endpoint: "ListUsersBeta", Params: map[string]string{}},
+		},
+		{
+			name:     "fallback without predicates",
+			request:  RequestLine{Method: "GET", Path: "/users"},
+			expected: RequestLineMatch[string]{Endpoint: "ListUsers", Params: map[string]string{}},
+		},
+		{
+			name: "content type",
+			request: RequestLine{
+				Method: "POST",
+				Path:   "/users",
+				Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			},
+			expected: RequestLineMatch[string]{Endpoint: "CreateUserJson", Params: map[string]string{}},
+		},
+		{
+			name:    "allowed methods respect predicates",
+			request: RequestLine{Method: "POST", Path: "/users"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func wrappingMiddleware(name string) Middleware[string] {
+	return func(endpoint string) string {
+		return name + "(" + endpoint + ")"
+	}
+}
+
+func TestRequestLineCompilerWith(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.With(wrappingMiddleware("Log"))(
+			dsl.Path("/users")(
+				dsl.With(wrappingMiddleware("Auth"), wrappingMiddleware("RateLimit"))(
+					dsl.Path("/me")(dsl.Get("ApiFetchSelf")),
+				),
+				dsl.Param("user_id")(dsl.Get("ApiFetchUser")),
+			),
+		),
+		dsl.Path("/")(dsl.Get("IndexRender")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected string
+	}{
+		{name: "nested middleware", request: RequestLine{Method: "GET", Path: "/users/me"}, expected: "Log(Auth(RateLimit(ApiFetchSelf)))"},
+		{name: "outer middleware", request: RequestLine{Method: "GET", Path: "/users/1337"}, expected: "Log(ApiFetchUser)"},
+		{name: "no middleware", request: RequestLine{Method: "GET", Path: "/"}, expected: "IndexRender"},
+		{name: "missing", request: RequestLine{Method: "GET", Path: "/idk"}, expected: "Missing"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match := routes(test.request)
+			if result := Chain(match.Middleware, match.Endpoint); result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineMatcherWithDoesNotAllocate(t *testing.T) {
+	dsl := NewRequestLineMatcherCompiler[string]()
+	matcher := dsl.Root("Missing")(
+		dsl.With(wrappingMiddleware("Log"))(dsl.Path("/users")(
+			dsl.Param("user_id")(dsl.With(wrappingMiddleware("Auth"))(dsl.Get("ApiFetchUser"))),
+		)),
+	)
+	result := matcher.NewResult()
+	request := RequestLine{Method: "GET", Path: "/users/1337"}
+	allocs := testing.AllocsPerRun(100, func() {
+		matcher.Match(request, &result)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+	if len(result.Middleware) != 2 {
+		t.Errorf("got %d middleware, want 2", len(result.Middleware))
+	}
+}
+
+func TestRequestLineCompilerMeta(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Meta("owner", "identity")(dsl.Path("/users")(
+			dsl.Meta("deprecated", true)(dsl.Meta("owner", "accounts")(dsl.Path("/me")(dsl.Get("ApiFetchSelf")))),
+			dsl.Param("user_id")(dsl.Get("ApiFetchUser")),
+		)),
+		dsl.Path("/")(dsl.Get("IndexRender")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected map[string]interface{}
+	}{
+		{
+			name:     "inner meta takes precedence",
+			request:  RequestLine{Method: "GET", Path: "/users/me"},
+			expected: map[string]interface{}{"owner": "accounts", "deprecated": true},
+		},
+		{
+			name:     "outer meta",
+			request:  RequestLine{Method: "GET", Path: "/users/1337"},
+			expected: map[string]interface{}{"owner": "identity"},
+		},
+		{name: "no meta", request: RequestLine{Method: "GET", Path: "/"}},
+		{name: "missing", request: RequestLine{Method: "GET", Path: "/idk"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := routes(test.request).Meta; !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineMatcherMeta(t *testing.T) {
+	dsl := NewRequestLineMatcherCompiler[string]()
+	matcher := dsl.Root("Missing")(
+		dsl.Meta("owner", "identity")(dsl.Path("/users")(
+			dsl.Meta("owner", "accounts")(dsl.Path("/me")(dsl.Get("ApiFetchSelf"))),
+		)),
+	)
+	result := matcher.NewResult()
+	request := RequestLine{Method: "GET", Path: "/users/me"}
+	allocs := testing.AllocsPerRun(100, func() {
+		matcher.Match(request, &result)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+	if owner, ok := result.Meta.Get("owner"); !ok || owner != "accounts" {
+		t.Errorf("got %v, want %v", owner, "accounts")
+	}
+	if _, ok := result.Meta.Get("scope"); ok {
+		t.Errorf("got scope, want none")
+	}
+}
+
+func makeBillingRoutes[Branch any, Out any](dsl Compiler[string, Branch, Out]) Branch {
+	return dsl.Path("/invoices")(
+		dsl.Get("ListInvoices"),
+		dsl.Param("invoice_id")(dsl.Get("FetchInvoice")),
+	)
+}
+
+func TestRequestLineCompilerMount(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.Root("Missing")(
+		dsl.Mount("/orgs/{org_id:int}/billing")(makeBillingRoutes[RequestLineBranch[string]](dsl)),
+		dsl.Mount("/api/v1")(makeBillingRoutes[RequestLineBranch[string]](dsl)),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:    "mounted under params",
+			request: RequestLine{Method: "GET", Path: "/orgs/42/billing/invoices/7"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "FetchInvoice",
+				Params:   map[string]string{"org_id": "42", "invoice_id": "7"},
+			},
+		},
+		{
+			name:    "mounted under constrained params mismatch",
+			request: RequestLine{Method: "GET", Path: "/orgs/acme/billing/invoices"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "Missing",
+				Params:   map[string]string{},
+			},
+		},
+		{
+			name:    "mounted under static prefix",
+			request: RequestLine{Method: "GET", Path: "/api/v1/invoices"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "ListInvoices",
+				Params:   map[string]string{},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := routes(test.request)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineCompilerCustomMethods(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/files")(
+			dsl.Method("PROPFIND", "DavListFiles"),
+			dsl.Method("MKCOL", "DavMakeCollection"),
+			dsl.Post("UploadFile"),
+		),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:     "custom method",
+			request:  RequestLine{Method: "PROPFIND", Path: "/files"},
+			expected: RequestLineMatch[string]{Endpoint: "DavListFiles", Params: map[string]string{}},
+		},
+		{
+			name:     "standard method alongside custom ones",
+			request:  RequestLine{Method: "POST", Path: "/files"},
+			expected: RequestLineMatch[string]{Endpoint: "UploadFile", Params: map[string]string{}},
+		},
+		{
+			name:    "custom methods are allowed",
+			request: RequestLine{Method: "QUERY", Path: "/files"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"PROPFIND", "MKCOL", "POST"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := routes(test.request); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLineCompilerAnyAndMethods(t *testing.T) {
+	dsl := NewRequestLineCompiler[string]()
+	routes := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/proxy/health")(dsl.Get("Health")),
+		dsl.Path("/proxy")(dsl.CatchAll("rest")(dsl.Any("Proxy"))),
+		dsl.Path("/files")(
+			dsl.Methods([]string{"GET", "HEAD"}, "FetchFile"),
+			dsl.Post("UploadFile"),
+		),
+		dsl.Path("/ping")(dsl.Any("PingAny"), dsl.Get("Ping")),
+		dsl.Path("/status")(dsl.Any("StatusAny")),
+		dsl.CatchAll("path")(dsl.Get("StaticFile")),
+	)
+	var tests = []struct {
+		name     string
+		request  RequestLine
+		expected RequestLineMatch[string]
+	}{
+		{
+			name:     "any method",
+			request:  RequestLine{Method: "PROPFIND", Path: "/proxy/a/b"},
+			expected: RequestLineMatch[string]{Endpoint: "Proxy", Params: map[string]string{"rest": "a/b"}},
+		},
+		{
+			name:     "explicit method at a more specific path",
+			request:  RequestLine{Method: "GET", Path: "/proxy/health"},
+			expected: RequestLineMatch[string]{Endpoint: "Health", Params: map[string]string{}},
+		},
+		{
+			name:     "any method where the explicit one doesn't match",
+			request:  RequestLine{Method: "POST", Path: "/proxy/health"},
+			expected: RequestLineMatch[string]{Endpoint: "Proxy", Params: map[string]string{"rest": "health"}},
+		},
+		{
+			name:     "explicit method declared after any at the same path",
+			request:  RequestLine{Method: "GET", Path: "/ping"},
+			expected: RequestLineMatch[string]{Endpoint: "Ping", Params: map[string]string{}},
+		},
+		{
+			name:     "any alongside an explicit method",
+			request:  RequestLine{Method: "DELETE", Path: "/ping"},
+			expected: RequestLineMatch[string]{Endpoint: "PingAny", Params: map[string]string{}},
+		},
+		{
+			name:     "any before an explicit method at another path",
+			request:  RequestLine{Method: "GET", Path: "/status"},
+			expected: RequestLineMatch[string]{Endpoint: "StatusAny", Params: map[string]string{}},
+		},
+		{
+			name:     "explicit method at another path",
+			request:  RequestLine{Method: "GET", Path: "/robots.txt"},
+			expected: RequestLineMatch[string]{Endpoint: "StaticFile", Params: map[string]string{"path": "robots.txt"}},
+		},
+		{
+			name:     "one of several methods",
+			request:  RequestLine{Method: "HEAD", Path: "/files"},
+			expected: RequestLineMatch[string]{Endpoint: "FetchFile", Params: map[string]string{}},
+		},
+		{
+			name:    "none of several methods",
+			request: RequestLine{Method: "DELETE", Path: "/files"},
+			expected: RequestLineMatch[string]{
+				Endpoint: "NotAllowed",
+				Params:   map[string]string{},
+				Allowed:  []string{"GET", "HEAD", "POST"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := routes(test.request); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+		})
+	}
+	automatic := NewRequestLineCompilerWithAutomaticMethods(describingOptions)
+	head := automatic.Root("Missing")(automatic.Path("/ping")(automatic.Any("PingAny"), automatic.Get("Ping")))
+	if result := head(RequestLine{Method: "HEAD", Path: "/ping"}); result.Endpoint != "Ping" {
+		t.Errorf("got %v, want automatic HEAD to reach %v before Any", result.Endpoint, "Ping")
+	}
+}
+
+func collideHostAndParam[Branch any, Out any](dsl Compiler[string, Branch, Out], hostOutside bool) Out {
+	if hostOutside {
+		return dsl.Root("Missing")(
+			dsl.Host("{id:int}.example.com")(dsl.Path("/users")(dsl.Param("id")(dsl.Get("U")))),
+		)
+	}
+	return dsl.Root("Missing")(
+		dsl.Path("/users")(dsl.Param("id")(dsl.Host("{id:int}.example.com")(dsl.Get("U")))),
+	)
+}
+
+func TestHostParamCollisionPanics(t *testing.T) {
+	var tests = []struct {
+		name    string
+		compile func(hostOutside bool)
+	}{
+		{name: "request line", compile: func(hostOutside bool) { collideHostAndParam(NewRequestLineCompiler[string](), hostOutside) }},
+		{name: "radix", compile: func(hostOutside bool) { collideHostAndParam(NewRadixCompiler[string](), hostOutside) }},
+		{name: "description", compile: func(hostOutside bool) { collideHostAndParam(NewDescriptionCompiler[string](), hostOutside) }},
+	}
+	for _, test := range tests {
+		for _, hostOutside := range []bool{true, false} {
+			where := " param outside host"
+			if hostOutside {
+				where = " host outside param"
+			}
+			t.Run(test.name+where, func(t *testing.T) {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("got no panic, want one")
+					}
+				}()
+				test.compile(hostOutside)
+			})
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("got no panic for a host capturing the same name twice, want one")
+		}
+	}()
+	NewRequestLineCompiler[string]().Host("{id}.{id}.example.com")
+}
+
+func nestHosts[Branch any, Out any](dsl Compiler[string, Branch, Out]) Out {
+	return dsl.Root("Missing")(
+		dsl.Host("a.example.com")(dsl.Host("b.example.com")(dsl.Path("/n")(dsl.Get("N")))),
+	)
+}
+
+func TestNestedHostPanics(t *testing.T) {
+	var tests = []struct {
+		name    string
+		compile func()
+	}{
+		{name: "request line", compile: func() { nestHosts(NewRequestLineCompiler[string]()) }},
+		{name: "radix", compile: func() { nestHosts(NewRadixCompiler[string]()) }},
+		{name: "description", compile: func() { nestHosts(NewDescriptionCompiler[string]()) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("got no panic, want one")
+				}
+			}()
+			test.compile()
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package http_routing
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownEndpoint = errors.New("unknown endpoint")
+	ErrMissingParam    = errors.New("missing param")
+	ErrExtraParam      = errors.New("extra param")
+	ErrInvalidParam    = errors.New("invalid param")
+)
+
+type ReverseRouterCompiler[Endpoint any, Key comparable] struct {
+	RadixCompiler[Endpoint]
+	key func(route RadixRoute[Endpoint]) (Key, bool)
+}
+
+func NewReverseRouterCompiler[Endpoint comparable]() Compiler[Endpoint, []RadixRoute[Endpoint], ReverseRouter[Endpoint]] {
+	return ReverseRouterCompiler[Endpoint, Endpoint]{key: func(route RadixRoute[Endpoint]) (Endpoint, bool) {
+		return route.endpoint, true
+	}}
+}
+
+func NewNamedReverseRouterCompiler[Endpoint any]() Compiler[Endpoint, []RadixRoute[Endpoint], ReverseRouter[string]] {
+	return ReverseRouterCompiler[Endpoint, string]{key: func(route RadixRoute[Endpoint]) (string, bool) {
+		return route.name, route.name != ""
+	}}
+}
+
+type ReverseRouter[Key comparable] struct {
+	routes map[Key]reverseRoute
+}
+
+type reverseRoute struct {
+	host     *hostPattern
+	segments []radixSegment
+}
+
+func (compiler ReverseRouterCompiler[Endpoint, Key]) Root(
+	missing Endpoint,
+) func(branches ...[]RadixRoute[Endpoint]) ReverseRouter[Key] {
+	return compiler.RootWithMethodNotAllowed(missing, missing)
+}
+
+func (compiler ReverseRouterCompiler[Endpoint, Key]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(branches ...[]RadixRoute[Endpoint]) ReverseRouter[Key] {
+	return func(branches ...[]RadixRoute[Endpoint]) ReverseRouter[Key] {
+		routes := make(map[Key]reverseRoute)
+		for _, route := range flatten(branches) {
+			key, ok := compiler.key(route)
+			if !ok {
+				continue
+			}
+			if _, ok := routes[key]; !ok {
+				routes[key] = reverseRoute{route.host, route.segments}
+			}
+		}
+		return ReverseRouter[Key]{routes}
+	}
+}
+
+func escapeCatchAll(value string) string {
+	pieces := strings.Split(value, "/")
+	for i, piece := range pieces {
+		pieces[i] = url.PathEscape(piece)
+	}
+	return strings.Join(pieces, "/")
+}
+
+func buildHost(host hostPattern, params map[string]string, used map[string]bool) (string, error) {
+	labels := make([]string, 0, len(host.labels))
+	for _, label := range host.labels {
+		if !label.capture {
+			labels = append(labels, label.value)
+			continue
+		}
+		value, ok := params[label.value]
+		if !ok {
+			return "", fmt.Errorf("%w %q", ErrMissingParam, label.value)
+		}
+		used[label.value] = true
+		if value == "" || strings.ContainsAny(value, ".:/") {
+			return "", fmt.Errorf("%w %q: %q is not a host label", ErrInvalidParam, label.value, value)
+		}
+		if !matchesConstraint(label.constraint, value) {
+			return "", fmt.Errorf("%w %q: %q is not %s", ErrInvalidParam, label.value, value, label.constraint)
+		}
+		labels = append(labels, value)
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func buildPath(segments []radixSegment, params map[string]string, used map[string]bool) (string, error) {
+	var builder strings.Builder
+	for _, segment := range segments {
+		if segment.kind == radixStatic {
+			builder.WriteString(segment.value)
+			continue
+		}
+		value, ok := params[segment.value]
+		if !ok {
+			return "", fmt.Errorf("%w %q", ErrMissingParam, segment.value)
+		}
+		used[segment.value] = true
+		builder.WriteString("/")
+		if segment.kind == radixCatchAll {
+			builder.WriteString(escapeCatchAll(value))
+			continue
+		}
+		if strings.Contains(value, "/") {
+			return "", fmt.Errorf("%w %q: %q is not a path segment", ErrInvalidParam, segment.value, value)
+		}
+		if !matchesConstraint(segment.constraint, value) {
+			return "", fmt.Errorf("%w %q: %q is not %s", ErrInvalidParam, segment.value, value, segment.constraint)
+		}
+		builder.WriteString(url.PathEscape(value))
+	}
+	return builder.String(), nil
+}
+
+// URL builds the path of the route, prefixed with "//" and its host when the
+// route is under Host, so host captures are filled from params like path
+// params are. The result is a network-path reference that keeps the scheme of
+// whatever page it is used on.
+func (router ReverseRouter[Key]) URL(key Key, params map[string]string) (string, error) {
+	route, ok := router.routes[key]
+	if !ok {
+		return "", fmt.Errorf("%w %v", ErrUnknownEndpoint, key)
+	}
+	used := make(map[string]bool, len(params))
+	host := ""
+	if route.host != nil {
+		built, err := buildHost(*route.host, params, used)
+		if err != nil {
+			return "", err
+		}
+		host = "//" + built
+	}
+	path, err := buildPath(route.segments, params, used)
+	if err != nil {
+		return "", err
+	}
+	extra := []string{}
+	for name := range params {
+		if !used[name] {
+			extra = append(extra, name)
+		}
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		return "", fmt.Errorf("%w %q", ErrExtraParam, strings.Join(extra, ", "))
+	}
+	if path == "" {
+		path = "/"
+	}
+	return host + path, nil
+}
@@ -0,0 +1,191 @@
+package http_routing
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReverseRouterCompiler(t *testing.T) {
+	dsl := NewReverseRouterCompiler[string]()
+	router := dsl.Root("Missing")(
+		dsl.Path("/")(dsl.Get("IndexRender")),
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.ConstrainedParam("user_id", IntConstraint())(
+				dsl.Get("ApiFetchUser"),
+				dsl.Head("ApiFetchUser"),
+				dsl.Path("/posts")(dsl.Param("post_id")(dsl.Get("ApiFetchUserPost"))),
+			),
+		),
+		dsl.Path("/static")(dsl.CatchAll("file")(dsl.Get("StaticFile"))),
+		dsl.Path("/users")(dsl.Get("ApiFetchUser")),
+		dsl.Get("Bare"),
+	)
+	var tests = []struct {
+		name     string
+		endpoint string
+		params   map[string]string
+		expected string
+		err      error
+	}{
+		{
+			name:     "static route",
+			endpoint: "IndexRender",
+			expected: "/",
+		},
+		{
+			name:     "static route with empty params",
+			endpoint: "ApiCreateUser",
+			params:   map[string]string{},
+			expected: "/users",
+		},
+		{
+			name:     "first declared route",
+			endpoint: "ApiFetchUser",
+			params:   map[string]string{"user_id": "1337"},
+			expected: "/users/1337",
+		},
+		{
+			name:     "multiple params",
+			endpoint: "ApiFetchUserPost",
+			params:   map[string]string{"user_id": "1337", "post_id": "hello world"},
+			expected: "/users/1337/posts/hello%20world",
+		},
+		{
+			name:     "param with a slash",
+			endpoint: "ApiFetchUserPost",
+			params:   map[string]string{"user_id": "1337", "post_id": "a/b"},
+			err:      ErrInvalidParam,
+		},
+		{
+			name:     "route without a path",
+			endpoint: "Bare",
+			expected: "/",
+		},
+		{
+			name:     "catch all keeps slashes",
+			endpoint: "StaticFile",
+			params:   map[string]string{"file": "css/site main.css"},
+			expected: "/static/css/site%20main.css",
+		},
+		{
+			name:     "unknown endpoint",
+			endpoint: "Missing",
+			err:      ErrUnknownEndpoint,
+		},
+		{
+			name:     "missing param",
+			endpoint: "ApiFetchUserPost",
+			params:   map[string]string{"user_id": "1337"},
+			err:      ErrMissingParam,
+		},
+		{
+			name:     "extra param",
+			endpoint: "ApiFetchUser",
+			params:   map[string]string{"user_id": "1337", "format": "json"},
+			err:      ErrExtraParam,
+		},
+		{
+			name:     "invalid param",
+			endpoint: "ApiFetchUser",
+			params:   map[string]string{"user_id": "me"},
+			err:      ErrInvalidParam,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := router.URL(test.endpoint, test.params)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestNamedReverseRouterCompiler(t *testing.T) {
+	dsl := NewNamedReverseRouterCompiler[func()]()
+	endpoint := func() {}
+	router := dsl.Root(endpoint)(
+		dsl.Path("/users")(
+			dsl.Post(endpoint),
+			dsl.Param("user_id")(dsl.Named("users.fetch")(dsl.Get(endpoint))),
+		),
+	)
+	result, err := router.URL("users.fetch", map[string]string{"user_id": "1337"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "/users/1337" {
+		t.Errorf("got %q, want %q", result, "/users/1337")
+	}
+	if _, err := router.URL("users.create", map[string]string{}); !errors.Is(err, ErrUnknownEndpoint) {
+		t.Errorf("got error %v, want %v", err, ErrUnknownEndpoint)
+	}
+}
+
+func TestReverseRouterCompilerHost(t *testing.T) {
+	dsl := NewReverseRouterCompiler[string]()
+	router := dsl.Root("Missing")(
+		dsl.Host("{tenant:enum(acme,globex)}.example.com")(
+			dsl.Path("/x")(dsl.Get("A")),
+			dsl.Get("TenantIndex"),
+		),
+		dsl.Path("/x")(dsl.Get("B")),
+	)
+	var tests = []struct {
+		name     string
+		endpoint string
+		params   map[string]string
+		expected string
+		err      error
+	}{
+		{
+			name:     "host capture",
+			endpoint: "A",
+			params:   map[string]string{"tenant": "acme"},
+			expected: "//acme.example.com/x",
+		},
+		{
+			name:     "host without a path",
+			endpoint: "TenantIndex",
+			params:   map[string]string{"tenant": "globex"},
+			expected: "//globex.example.com/",
+		},
+		{
+			name:     "no host",
+			endpoint: "B",
+			expected: "/x",
+		},
+		{
+			name:     "missing host capture",
+			endpoint: "A",
+			err:      ErrMissingParam,
+		},
+		{
+			name:     "host capture failing its constraint",
+			endpoint: "A",
+			params:   map[string]string{"tenant": "initech"},
+			err:      ErrInvalidParam,
+		},
+		{
+			name:     "host capture spanning labels",
+			endpoint: "A",
+			params:   map[string]string{"tenant": "acme.evil"},
+			err:      ErrInvalidParam,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := router.URL(test.endpoint, test.params)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if result != test.expected {
+				t.Errorf("got %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
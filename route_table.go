@@ -0,0 +1,382 @@
+package http_routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type RouteTablePosition struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (position RouteTablePosition) String() string {
+	return position.Filename + ":" + strconv.Itoa(position.Line) + ":" + strconv.Itoa(position.Column)
+}
+
+type RouteTableError struct {
+	Position RouteTablePosition
+	Err      error
+}
+
+func (err RouteTableError) Error() string {
+	return err.Position.String() + ": " + err.Err.Error()
+}
+
+func (err RouteTableError) Unwrap() error {
+	return err.Err
+}
+
+type RouteTableErrors []RouteTableError
+
+func (errs RouteTableErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+type routeTableValue struct {
+	value    string
+	position RouteTablePosition
+}
+
+type routeTableEntry struct {
+	position RouteTablePosition
+	fields   map[string]routeTableValue
+}
+
+func (entry routeTableEntry) set(key string, value routeTableValue) error {
+	if _, ok := entry.fields[key]; ok {
+		return RouteTableError{value.position, fmt.Errorf("duplicate field %q", key)}
+	}
+	entry.fields[key] = value
+	return nil
+}
+
+func parseHttpMethod(method string) (httpMethod, error) {
+	if !httpMethod(method).valid() {
+		return "", fmt.Errorf("%w %q", ErrUnknownMethod, method)
+	}
+	return httpMethod(method), nil
+}
+
+var routeTableFields = map[string]bool{"method": true, "path": true, "endpoint": true, "name": true}
+
+func routeFromTableEntry[Endpoint any](
+	entry routeTableEntry,
+	registry map[string]Endpoint,
+) (FlatRoute[Endpoint], RouteTableErrors) {
+	errs := RouteTableErrors{}
+	for key, value := range entry.fields {
+		if !routeTableFields[key] {
+			errs = append(errs, RouteTableError{value.position, fmt.Errorf("unknown field %q", key)})
+		}
+	}
+	for _, key := range []string{"method", "path", "endpoint"} {
+		if _, ok := entry.fields[key]; !ok {
+			errs = append(errs, RouteTableError{entry.position, fmt.Errorf("missing field %q", key)})
+		}
+	}
+	if len(errs) > 0 {
+		return FlatRoute[Endpoint]{}, errs
+	}
+	method, err := parseHttpMethod(entry.fields["method"].value)
+	if err != nil {
+		errs = append(errs, RouteTableError{entry.fields["method"].position, err})
+	}
+	path := entry.fields["path"]
+	for _, err := range flatPathErrors(path.value) {
+		errs = append(errs, RouteTableError{path.position, err})
+	}
+	name := entry.fields["endpoint"]
+	endpoint, ok := registry[name.value]
+	if !ok {
+		errs = append(errs, RouteTableError{name.position, fmt.Errorf("%w %q", ErrUnknownEndpoint, name.value)})
+	}
+	if len(errs) > 0 {
+		return FlatRoute[Endpoint]{}, errs
+	}
+	route := newFlatRoute(method, path.value, endpoint)
+	route.name = entry.fields["name"].value
+	return route, nil
+}
+
+func routesFromTable[Endpoint any](entries []routeTableEntry, registry map[string]Endpoint) ([]FlatRoute[Endpoint], error) {
+	routes := make([]FlatRoute[Endpoint], 0, len(entries))
+	errs := RouteTableErrors{}
+	for _, entry := range entries {
+		route, entryErrs := routeFromTableEntry(entry, registry)
+		if len(entryErrs) > 0 {
+			errs = append(errs, entryErrs...)
+			continue
+		}
+		routes = append(routes, route)
+	}
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			a, b := errs[i].Position, errs[j].Position
+			return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+		})
+		return nil, errs
+	}
+	return routes, nil
+}
+
+func offsetPosition(filename string, data []byte, offset int) RouteTablePosition {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return RouteTablePosition{filename, line, column}
+}
+
+func skipJSONSeparators(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func parseJSONRouteTable(filename string, data []byte) ([]routeTableEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	fail := func(offset int, err error) error {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			offset = int(syntax.Offset)
+		}
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return RouteTableError{offsetPosition(filename, data, offset), err}
+	}
+	expectDelim := func(expected json.Delim, what string) error {
+		start := skipJSONSeparators(data, int(decoder.InputOffset()))
+		token, err := decoder.Token()
+		if err != nil {
+			return fail(start, err)
+		}
+		if token != expected {
+			return fail(start, fmt.Errorf("expected %s", what))
+		}
+		return nil
+	}
+	if err := expectDelim('[', "a list of routes"); err != nil {
+		return nil, err
+	}
+	entries := []routeTableEntry{}
+	for decoder.More() {
+		start := skipJSONSeparators(data, int(decoder.InputOffset()))
+		if err := expectDelim('{', "a route object"); err != nil {
+			return nil, err
+		}
+		entry := routeTableEntry{offsetPosition(filename, data, start), map[string]routeTableValue{}}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, fail(int(decoder.InputOffset()), err)
+			}
+			key := token.(string)
+			valueStart := skipJSONSeparators(data, int(decoder.InputOffset()))
+			token, err = decoder.Token()
+			if err != nil {
+				return nil, fail(valueStart, err)
+			}
+			value, ok := token.(string)
+			if !ok {
+				return nil, fail(valueStart, fmt.Errorf("field %q must be a string", key))
+			}
+			if err := entry.set(key, routeTableValue{value, offsetPosition(filename, data, valueStart)}); err != nil {
+				return nil, err
+			}
+		}
+		if err := expectDelim('}', "end of route object"); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := expectDelim(']', "end of route list"); err != nil {
+		return nil, err
+	}
+	end := int(decoder.InputOffset())
+	if rest := bytes.TrimLeft(data[end:], " \t\r\n"); len(rest) > 0 {
+		return nil, fail(len(data)-len(rest), errors.New("unexpected data after route list"))
+	}
+	return entries, nil
+}
+
+func parseYAMLScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := 1
+		for end < len(raw) && raw[end] != '"' {
+			if raw[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(raw) {
+			return "", errors.New("unterminated double quoted value")
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+		var value string
+		if err := json.Unmarshal([]byte(raw[:end+1]), &value); err != nil {
+			return "", fmt.Errorf("invalid double quoted value: %w", err)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		var builder strings.Builder
+		for i := 1; i < len(raw); i++ {
+			if raw[i] != '\'' {
+				builder.WriteByte(raw[i])
+				continue
+			}
+			if i+1 < len(raw) && raw[i+1] == '\'' {
+				builder.WriteByte('\'')
+				i++
+				continue
+			}
+			if rest := strings.TrimSpace(raw[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected %q after quoted value", rest)
+			}
+			return builder.String(), nil
+		}
+		return "", errors.New("unterminated single quoted value")
+	case raw == "" || strings.HasPrefix(raw, "#"):
+		return "", errors.New("missing value")
+	case strings.ContainsAny(raw[:1], "{[&*!|>%@`"):
+		return "", fmt.Errorf("unsupported value %q, quote it", raw)
+	}
+	if comment := strings.Index(raw, " #"); comment != -1 {
+		raw = raw[:comment]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// yamlDocumentMarker reports whether line is marker, "---" or "...", on its
+// own or followed by a comment.
+func yamlDocumentMarker(line string, marker string) bool {
+	rest := strings.TrimPrefix(line, marker)
+	if rest == line {
+		return false
+	}
+	trimmed := strings.TrimLeft(rest, " ")
+	return trimmed == "" || (trimmed != rest && strings.HasPrefix(trimmed, "#"))
+}
+
+// parseYAMLRouteTable reads the block sequence of flat mappings a route table
+// needs, with plain, single and double quoted scalars and comments; anchors,
+// flow collections and multi-line scalars are rejected rather than guessed at.
+func parseYAMLRouteTable(filename string, data []byte) ([]routeTableEntry, error) {
+	entries := []routeTableEntry{}
+	listIndent, keyIndent := -1, -1
+	started, ended := false, false
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		content := strings.TrimLeft(line, " ")
+		column := len(line) - len(content) + 1
+		position := RouteTablePosition{filename, i + 1, column}
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		if ended || (started && yamlDocumentMarker(line, "---")) {
+			return nil, RouteTableError{position, errors.New("only one YAML document is supported")}
+		}
+		if yamlDocumentMarker(line, "---") || yamlDocumentMarker(line, "...") {
+			started = true
+			ended = strings.HasPrefix(line, "...")
+			continue
+		}
+		started = true
+		if content == "[]" && len(entries) == 0 {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, RouteTableError{position, errors.New("tabs are not allowed for indentation")}
+		}
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			if listIndent == -1 {
+				listIndent = column
+			}
+			if column != listIndent {
+				return nil, RouteTableError{position, errors.New("unexpected indentation")}
+			}
+			entries = append(entries, routeTableEntry{position, map[string]routeTableValue{}})
+			rest := strings.TrimLeft(content[1:], " ")
+			if rest == "" {
+				keyIndent = -1
+				continue
+			}
+			column += len(content) - len(rest)
+			content = rest
+			keyIndent = column
+		} else if len(entries) == 0 {
+			return nil, RouteTableError{position, errors.New("expected a list of routes")}
+		} else if keyIndent == -1 {
+			keyIndent = column
+		}
+		if column != keyIndent || column <= listIndent {
+			return nil, RouteTableError{RouteTablePosition{filename, i + 1, column}, errors.New("unexpected indentation")}
+		}
+		if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+			return nil, RouteTableError{RouteTablePosition{filename, i + 1, column}, errors.New("flow collections are not supported")}
+		}
+		key, raw, ok := strings.Cut(content, ":")
+		if !ok || strings.ContainsAny(key, " \"'") {
+			return nil, RouteTableError{RouteTablePosition{filename, i + 1, column}, errors.New("expected key: value")}
+		}
+		trimmed := strings.TrimLeft(raw, " ")
+		valuePosition := RouteTablePosition{filename, i + 1, column + len(key) + 1 + len(raw) - len(trimmed)}
+		value, err := parseYAMLScalar(trimmed)
+		if err != nil {
+			return nil, RouteTableError{valuePosition, err}
+		}
+		if err := entries[len(entries)-1].set(key, routeTableValue{value, valuePosition}); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// LoadJSON reads a JSON list of {method, path, endpoint, name} objects, naming
+// endpoints by their key in registry, and returns routes for Root. Errors carry
+// the filename, line and column of the offending value.
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) LoadJSON(
+	filename string,
+	data []byte,
+	registry map[string]Endpoint,
+) ([]FlatRoute[Endpoint], error) {
+	entries, err := parseJSONRouteTable(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	return routesFromTable(entries, registry)
+}
+
+// LoadYAML is LoadJSON for a YAML block sequence of the same entries. It reads
+// the subset of YAML a route table needs: one document, optionally opened by
+// --- and closed by ..., holding a list of flat mappings whose values are plain
+// or quoted scalars. Anchors, tags, flow collections and multi-line scalars are
+// rejected with their position rather than guessed at.
+func (transpiler *FlatRouteTranspiler[Endpoint, Branch, Root]) LoadYAML(
+	filename string,
+	data []byte,
+	registry map[string]Endpoint,
+) ([]FlatRoute[Endpoint], error) {
+	entries, err := parseYAMLRouteTable(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	return routesFromTable(entries, registry)
+}
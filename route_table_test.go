@@ -0,0 +1,250 @@
+package http_routing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var routeTableRegistry = map[string]string{
+	"IndexRender":  "IndexRender",
+	"ApiFetchUser": "ApiFetchUser",
+	"StaticFile":   "StaticFile",
+}
+
+func TestFlatRouteTranspilerLoad(t *testing.T) {
+	dsl := NewFlatRouteTranspiler(NewRequestLineCompiler[string]())
+	jsonTable := []byte(`[
+  {"method": "GET", "path": "/", "endpoint": "IndexRender"},
+  {"method": "GET", "path": "/users/{user_id:int}", "endpoint": "ApiFetchUser", "name": "users.fetch"},
+  {"method": "GET", "path": "/static/{file...}", "endpoint": "StaticFile"}
+]`)
+	yamlTable := []byte(`# routes
+- method: GET
+  path: /
+  endpoint: IndexRender
+-
+  method: "GET"
+  path: '/users/{user_id:int}'
+  endpoint: ApiFetchUser # by id
+  name: users.fetch
+- method: GET
+  path: "/static/{file...}"
+  endpoint: StaticFile
+`)
+	var tests = []struct {
+		name string
+		load func() ([]FlatRoute[string], error)
+	}{
+		{name: "json", load: func() ([]FlatRoute[string], error) {
+			return dsl.LoadJSON("routes.json", jsonTable, routeTableRegistry)
+		}},
+		{name: "yaml", load: func() ([]FlatRoute[string], error) {
+			return dsl.LoadYAML("routes.yaml", yamlTable, routeTableRegistry)
+		}},
+		{name: "yaml document", load: func() ([]FlatRoute[string], error) {
+			return dsl.LoadYAML("routes.yaml", []byte(`--- # routes
+  - method: GET
+    path: /users/{user_id:int}
+    endpoint: ApiFetchUser
+    name: users.fetch
+  - method: GET
+    path: /static/{file...}
+    endpoint: StaticFile
+...
+`), routeTableRegistry)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loaded, err := test.load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			routes := dsl.Root("Missing")(loaded...)
+			result := routes(RequestLine{Method: "GET", Path: "/users/1337"})
+			expected := RequestLineMatch[string]{
+				Endpoint: "ApiFetchUser",
+				Params:   map[string]string{"user_id": "1337"},
+				Name:     "users.fetch",
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("got %+v, want %+v", result, expected)
+			}
+			result = routes(RequestLine{Method: "GET", Path: "/static/css/main.css"})
+			if result.Endpoint != "StaticFile" {
+				t.Errorf("got %v, want %v", result.Endpoint, "StaticFile")
+			}
+		})
+	}
+}
+
+func TestFlatRouteTranspilerLoadErrors(t *testing.T) {
+	dsl := NewFlatRouteTranspiler(NewRequestLineCompiler[string]())
+	var tests = []struct {
+		name     string
+		load     func() ([]FlatRoute[string], error)
+		expected string
+		is       error
+	}{
+		{
+			name: "json unknown method",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte(`[
+  {"method": "brew", "path": "/", "endpoint": "IndexRender"}
+]`), routeTableRegistry)
+			},
+			expected: `routes.json:2:14: unknown method "brew"`,
+			is:       ErrUnknownMethod,
+		},
+		{
+			name: "json numeric method",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte(`[{"method": "123", "path": "/", "endpoint": "IndexRender"}]`), routeTableRegistry)
+			},
+			expected: `routes.json:1:13: unknown method "123"`,
+			is:       ErrUnknownMethod,
+		},
+		{
+			name: "yaml punctuation method",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- method: \"-\"\n  path: /\n  endpoint: IndexRender\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:1:11: unknown method "-"`,
+			is:       ErrUnknownMethod,
+		},
+		{
+			name: "json malformed path",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte(`[{"method": "GET", "path": "/users/{user_id", "endpoint": "ApiFetchUser"}]`), routeTableRegistry)
+			},
+			expected: `routes.json:1:28: malformed path: unbalanced braces "{user_id"`,
+			is:       ErrMalformedPath,
+		},
+		{
+			name: "json unknown endpoint",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte(`[{"method": "GET", "path": "/", "endpoint": "Nope"}]`), routeTableRegistry)
+			},
+			expected: `routes.json:1:45: unknown endpoint "Nope"`,
+			is:       ErrUnknownEndpoint,
+		},
+		{
+			name: "json syntax",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte("[\n  {\"method\" \"GET\"}\n]"), routeTableRegistry)
+			},
+			expected: `routes.json:2:14: invalid character '"' after object key`,
+		},
+		{
+			name: "json trailing data",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte("[{\"method\": \"GET\", \"path\": \"/\", \"endpoint\": \"IndexRender\"}]\n trailing"), routeTableRegistry)
+			},
+			expected: `routes.json:2:2: unexpected data after route list`,
+		},
+		{
+			name: "json non string value",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadJSON("routes.json", []byte(`[{"method": 1}]`), routeTableRegistry)
+			},
+			expected: `routes.json:1:13: field "method" must be a string`,
+		},
+		{
+			name: "yaml errors in order",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte(`- method: brew
+  path: /users//{user_id}
+  endpoint: Nope
+- method: GET
+  path: /
+  colour: blue
+`), routeTableRegistry)
+			},
+			expected: `routes.yaml:1:11: unknown method "brew"
+routes.yaml:2:9: malformed path: empty segment
+routes.yaml:3:13: unknown endpoint "Nope"
+routes.yaml:4:1: missing field "endpoint"
+routes.yaml:6:11: unknown field "colour"`,
+			is: ErrUnknownMethod,
+		},
+		{
+			name: "yaml flow value",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- method: GET\n  path: {user_id}\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:2:9: unsupported value "{user_id}", quote it`,
+		},
+		{
+			name: "yaml flow mapping",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- {method: GET, path: /, endpoint: IndexRender}\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:1:3: flow collections are not supported`,
+		},
+		{
+			name: "yaml indentation",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- method: GET\n    path: /\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:2:5: unexpected indentation`,
+		},
+		{
+			name: "yaml uneven list",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("  - method: GET\n- method: POST\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:2:1: unexpected indentation`,
+		},
+		{
+			name: "yaml second document",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("---\n- method: GET\n---\n- method: POST\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:3:1: only one YAML document is supported`,
+		},
+		{
+			name: "yaml content after document end",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- method: GET\n...\n- method: POST\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:3:1: only one YAML document is supported`,
+		},
+		{
+			name: "yaml anchor",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- method: &get GET\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:1:11: unsupported value "&get GET", quote it`,
+		},
+		{
+			name: "yaml block scalar",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- path: |\n    /users\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:1:9: unsupported value "|", quote it`,
+		},
+		{
+			name: "yaml duplicate field",
+			load: func() ([]FlatRoute[string], error) {
+				return dsl.LoadYAML("routes.yaml", []byte("- method: GET\n  method: POST\n"), routeTableRegistry)
+			},
+			expected: `routes.yaml:2:11: duplicate field "method"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.load()
+			if err == nil {
+				t.Fatalf("got no error, want %q", test.expected)
+			}
+			if err.Error() != test.expected {
+				t.Errorf("got %q, want %q", err.Error(), test.expected)
+			}
+			var errs RouteTableErrors
+			if test.is != nil && (!errors.As(err, &errs) || !errors.Is(errs[0], test.is)) {
+				t.Errorf("got %v, want %v", err, test.is)
+			}
+		})
+	}
+}
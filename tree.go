@@ -0,0 +1,169 @@
+package http_routing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RouteTreeCompiler keeps the nesting the DSL expresses, one node per
+// combinator, so it can be printed as an indented tree or a Graphviz graph.
+type RouteTreeCompiler[Endpoint any] struct{}
+
+func NewRouteTreeCompiler[Endpoint any]() Compiler[Endpoint, RouteTree, RouteTree] {
+	return RouteTreeCompiler[Endpoint]{}
+}
+
+type RouteTree struct {
+	Label    string
+	Children []RouteTree
+}
+
+func (tree RouteTree) appendASCII(out []byte, prefix string) []byte {
+	for i, child := range tree.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(tree.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		out = append(out, prefix+branch+child.Label+"\n"...)
+		out = child.appendASCII(out, prefix+indent)
+	}
+	return out
+}
+
+func (tree RouteTree) ASCII() string {
+	return string(tree.appendASCII([]byte(tree.Label+"\n"), ""))
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (tree RouteTree) appendDOT(out []byte, id int, next int) ([]byte, int) {
+	out = append(out, "  n"+strconv.Itoa(id)+` [label="`+dotEscaper.Replace(tree.Label)+`"];`+"\n"...)
+	for _, child := range tree.Children {
+		childId := next
+		out = append(out, "  n"+strconv.Itoa(id)+" -> n"+strconv.Itoa(childId)+";\n"...)
+		out, next = child.appendDOT(out, childId, childId+1)
+	}
+	return out, next
+}
+
+func (tree RouteTree) DOT() string {
+	out, _ := tree.appendDOT([]byte("digraph routes {\n"), 0, 1)
+	return string(append(out, "}\n"...))
+}
+
+func routeTreeNode(label string) func(branches ...RouteTree) RouteTree {
+	return func(branches ...RouteTree) RouteTree {
+		return RouteTree{label, branches}
+	}
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Root(
+	missing Endpoint,
+) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode(fmt.Sprintf("root (missing: %v)", missing))
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode(fmt.Sprintf("root (missing: %v, method not allowed: %v)", missing, methodNotAllowed))
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Path(prefix string) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode(prefix)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Param(name string) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("/{" + name + "}")
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) ConstrainedParam(
+	name string,
+	constraint ParamConstraint,
+) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("/{" + name + ":" + constraint.String() + "}")
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) CatchAll(name string) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("/{" + name + "...}")
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Mount(prefix string) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("mount " + prefix)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Named(name string) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("named " + name)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Host(pattern string) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("host " + pattern)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Where(predicate RequestPredicate) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode("where " + predicate.String())
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) With(
+	middleware ...Middleware[Endpoint],
+) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode(fmt.Sprintf("with %d middleware", len(middleware)))
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Meta(key string, value interface{}) func(branches ...RouteTree) RouteTree {
+	return routeTreeNode(fmt.Sprintf("meta %s=%v", key, value))
+}
+
+func routeTreeMethod[Endpoint any](method string, endpoint Endpoint) RouteTree {
+	return RouteTree{Label: fmt.Sprintf("%s %v", method, endpoint)}
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Method(name string, endpoint Endpoint) RouteTree {
+	return routeTreeMethod(name, endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Methods(names []string, endpoint Endpoint) RouteTree {
+	return routeTreeMethod(strings.Join(names, "|"), endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Any(endpoint Endpoint) RouteTree {
+	return routeTreeMethod("ANY", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Get(endpoint Endpoint) RouteTree {
+	return compiler.Method("GET", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Post(endpoint Endpoint) RouteTree {
+	return compiler.Method("POST", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Put(endpoint Endpoint) RouteTree {
+	return compiler.Method("PUT", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Delete(endpoint Endpoint) RouteTree {
+	return compiler.Method("DELETE", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Options(endpoint Endpoint) RouteTree {
+	return compiler.Method("OPTIONS", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Patch(endpoint Endpoint) RouteTree {
+	return compiler.Method("PATCH", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Head(endpoint Endpoint) RouteTree {
+	return compiler.Method("HEAD", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Connect(endpoint Endpoint) RouteTree {
+	return compiler.Method("CONNECT", endpoint)
+}
+
+func (compiler RouteTreeCompiler[Endpoint]) Trace(endpoint Endpoint) RouteTree {
+	return compiler.Method("TRACE", endpoint)
+}
@@ -0,0 +1,69 @@
+package http_routing
+
+import (
+	"testing"
+)
+
+func makeTreeRoutes[Branch any, Out any](dsl Compiler[string, Branch, Out]) Out {
+	return dsl.Root("Missing")(
+		dsl.Path("/")(dsl.Get("IndexRender")),
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.Param("user_id")(
+				dsl.Named("users.fetch")(dsl.Get("ApiFetchUser")),
+				dsl.Delete("ApiDeleteUser"),
+			),
+		),
+		dsl.Mount("/static")(dsl.CatchAll("file")(dsl.Get("Static \"file\""))),
+	)
+}
+
+func TestRouteTreeASCII(t *testing.T) {
+	tree := makeTreeRoutes(NewRouteTreeCompiler[string]())
+	expected := `root (missing: Missing)
+├── /
+│   └── GET IndexRender
+├── /users
+│   ├── POST ApiCreateUser
+│   └── /{user_id}
+│       ├── named users.fetch
+│       │   └── GET ApiFetchUser
+│       └── DELETE ApiDeleteUser
+└── mount /static
+    └── /{file...}
+        └── GET Static "file"
+`
+	if result := tree.ASCII(); result != expected {
+		t.Errorf("got\n%s\nwant\n%s", result, expected)
+	}
+}
+
+func TestRouteTreeDOT(t *testing.T) {
+	dsl := NewRouteTreeCompiler[string]()
+	tree := dsl.RootWithMethodNotAllowed("Missing", "NotAllowed")(
+		dsl.Path("/users")(
+			dsl.Post("ApiCreateUser"),
+			dsl.ConstrainedParam("user_id", IntConstraint())(dsl.Get("ApiFetchUser")),
+		),
+		dsl.Path("/")(dsl.Get("Index \"page\"")),
+	)
+	expected := `digraph routes {
+  n0 [label="root (missing: Missing, method not allowed: NotAllowed)"];
+  n0 -> n1;
+  n1 [label="/users"];
+  n1 -> n2;
+  n2 [label="POST ApiCreateUser"];
+  n1 -> n3;
+  n3 [label="/{user_id:int}"];
+  n3 -> n4;
+  n4 [label="GET ApiFetchUser"];
+  n0 -> n5;
+  n5 [label="/"];
+  n5 -> n6;
+  n6 [label="GET Index \"page\""];
+}
+`
+	if result := tree.DOT(); result != expected {
+		t.Errorf("got\n%s\nwant\n%s", result, expected)
+	}
+}
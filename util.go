@@ -33,14 +33,13 @@ func flattenThenMap[T any, U any](nested [][]T, f func(T) U) []U {
 	return out
 }
 
-func mapFind[T any, U any](slice []T, mapper func(item T) *U) *U {
-	for _, item := range slice {
-		result := mapper(item)
-		if result != nil {
-			return result
+func appendMissing(slice []string, value string) []string {
+	for _, existing := range slice {
+		if existing == value {
+			return slice
 		}
 	}
-	return nil
+	return append(slice, value)
 }
 
 func takeUntilByte(str string, target byte) (string, string) {
@@ -50,3 +49,15 @@ func takeUntilByte(str string, target byte) (string, string) {
 	}
 	return str[:index], str[index:]
 }
+
+func withOuterMeta(meta map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if _, ok := meta[key]; ok {
+		return meta
+	}
+	out := make(map[string]interface{}, len(meta)+1)
+	for existing, value := range meta {
+		out[existing] = value
+	}
+	out[key] = value
+	return out
+}
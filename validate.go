@@ -0,0 +1,240 @@
+package http_routing
+
+import (
+	"strings"
+)
+
+// ValidatingCompiler reports conflicts as a first-match compiler such as
+// RequestLineCompiler would resolve them, checking each route against every
+// route declared before it.
+type ValidatingCompiler[Endpoint any] struct {
+	RadixCompiler[Endpoint]
+}
+
+func NewValidatingCompiler[Endpoint any]() Compiler[Endpoint, []RadixRoute[Endpoint], []RouteConflict] {
+	return ValidatingCompiler[Endpoint]{}
+}
+
+type RouteConflictKind string
+
+const (
+	DuplicateRoute   RouteConflictKind = "duplicate"
+	UnreachableRoute RouteConflictKind = "unreachable"
+	AmbiguousRoute   RouteConflictKind = "ambiguous"
+)
+
+type RouteConflict struct {
+	Kind          RouteConflictKind
+	Method        string
+	Path          string
+	ConflictsWith string
+}
+
+func (conflict RouteConflict) String() string {
+	out := string(conflict.Kind) + " route " + conflict.Method + " " + conflict.Path
+	if conflict.ConflictsWith != "" {
+		out += " conflicts with " + conflict.Method + " " + conflict.ConflictsWith
+	}
+	return out
+}
+
+func renderRadixPath(segments []radixSegment) string {
+	var builder strings.Builder
+	for _, segment := range segments {
+		switch segment.kind {
+		case radixStatic:
+			builder.WriteString(segment.value)
+		case radixParam:
+			builder.WriteString("/{" + segment.value)
+			if segment.constraint != nil {
+				builder.WriteString(":" + segment.constraint.String())
+			}
+			builder.WriteString("}")
+		case radixCatchAll:
+			builder.WriteString("/{" + segment.value + "...}")
+		}
+	}
+	return builder.String()
+}
+
+func renderRadixRoute[Endpoint any](route RadixRoute[Endpoint]) string {
+	path := renderRadixPath(route.segments) + renderPredicates(route.predicates)
+	if route.host == nil {
+		return path
+	}
+	return route.host.pattern + path
+}
+
+func conditionsCover[Endpoint any](a RadixRoute[Endpoint], b RadixRoute[Endpoint]) bool {
+	return hostCovers(a.host, b.host) && coversPredicates(a.predicates, b.predicates)
+}
+
+func hostCovers(a *hostPattern, b *hostPattern) bool {
+	return a == nil || (b != nil && a.pattern == b.pattern)
+}
+
+func hostsOverlap(a *hostPattern, b *hostPattern) bool {
+	return hostCovers(a, b) || hostCovers(b, a)
+}
+
+func validationSegments(segments []radixSegment) ([]radixSegment, bool) {
+	out := []radixSegment{{kind: radixStatic}}
+	for _, segment := range segments {
+		last := &out[len(out)-1]
+		if last.kind == radixCatchAll && (segment.kind != radixStatic || segment.value != "") {
+			return out, false
+		}
+		if segment.kind != radixStatic {
+			out = append(out, radixSegment{segment.kind, "", segment.constraint})
+			continue
+		}
+		pieces := strings.Split(segment.value, "/")
+		if last.kind != radixStatic && pieces[0] != "" {
+			return out, false
+		}
+		last.value += pieces[0]
+		for _, piece := range pieces[1:] {
+			out = append(out, radixSegment{radixStatic, piece, nil})
+		}
+	}
+	return out, true
+}
+
+func segmentCovers(a radixSegment, b radixSegment) bool {
+	switch {
+	case a.kind == radixStatic:
+		return b.kind == radixStatic && a.value == b.value
+	case a.constraint == nil:
+		return true
+	case b.kind == radixStatic:
+		return a.constraint.Matches(b.value)
+	}
+	return sameConstraint(a.constraint, b.constraint)
+}
+
+func segmentsOverlap(a radixSegment, b radixSegment) bool {
+	switch {
+	case a.kind == radixStatic && b.kind == radixStatic:
+		return a.value == b.value
+	case a.kind == radixStatic:
+		return matchesConstraint(b.constraint, a.value)
+	case b.kind == radixStatic:
+		return matchesConstraint(a.constraint, b.value)
+	}
+	return true
+}
+
+func covers(a []radixSegment, b []radixSegment) bool {
+	for i, segment := range a {
+		if segment.kind == radixCatchAll {
+			return len(b) > i
+		}
+		if i >= len(b) || b[i].kind == radixCatchAll || !segmentCovers(segment, b[i]) {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func overlaps(a []radixSegment, b []radixSegment) bool {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i >= len(a) || i >= len(b) {
+			return false
+		}
+		if a[i].kind == radixCatchAll || b[i].kind == radixCatchAll {
+			return true
+		}
+		if !segmentsOverlap(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func capturesStatic(a []radixSegment, b []radixSegment) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].kind == radixCatchAll {
+			for _, segment := range b[i:] {
+				if segment.kind == radixStatic {
+					return true
+				}
+			}
+			return false
+		}
+		if a[i].kind != radixStatic && b[i].kind == radixStatic {
+			return true
+		}
+	}
+	return false
+}
+
+// methodsOverlap treats Any as every method. Explicit methods win over Any
+// declared at the same path, so only routes at different paths conflict.
+func methodsOverlap(a string, b string) bool {
+	return a == b || a == AnyMethod || b == AnyMethod
+}
+
+func findConflict[Endpoint any](
+	earlier []RadixRoute[Endpoint],
+	earlierSegments [][]radixSegment,
+	route RadixRoute[Endpoint],
+	segments []radixSegment,
+) (RouteConflictKind, int, bool) {
+	ambiguous := -1
+	for j, other := range earlier {
+		if !methodsOverlap(other.method, route.method) || earlierSegments[j] == nil || !hostsOverlap(other.host, route.host) {
+			continue
+		}
+		if other.method != route.method && covers(earlierSegments[j], segments) && covers(segments, earlierSegments[j]) {
+			continue
+		}
+		shadowsAll := other.method == route.method || other.method == AnyMethod
+		if shadowsAll && conditionsCover(other, route) && covers(earlierSegments[j], segments) {
+			if conditionsCover(route, other) && covers(segments, earlierSegments[j]) {
+				return DuplicateRoute, j, true
+			}
+			return UnreachableRoute, j, true
+		}
+		if ambiguous == -1 && overlaps(earlierSegments[j], segments) && capturesStatic(earlierSegments[j], segments) {
+			ambiguous = j
+		}
+	}
+	if ambiguous != -1 {
+		return AmbiguousRoute, ambiguous, true
+	}
+	return "", 0, false
+}
+
+func validateRoutes[Endpoint any](routes []RadixRoute[Endpoint]) []RouteConflict {
+	conflicts := []RouteConflict{}
+	normalized := make([][]radixSegment, len(routes))
+	for i, route := range routes {
+		path := renderRadixRoute(route)
+		segments, reachable := validationSegments(route.segments)
+		if !reachable {
+			conflicts = append(conflicts, RouteConflict{UnreachableRoute, route.method, path, ""})
+			continue
+		}
+		normalized[i] = segments
+		if kind, j, ok := findConflict(routes[:i], normalized[:i], route, segments); ok {
+			other := renderRadixRoute(routes[j])
+			conflicts = append(conflicts, RouteConflict{kind, route.method, path, other})
+		}
+	}
+	return conflicts
+}
+
+func (compiler ValidatingCompiler[Endpoint]) Root(
+	missing Endpoint,
+) func(branches ...[]RadixRoute[Endpoint]) []RouteConflict {
+	return compiler.RootWithMethodNotAllowed(missing, missing)
+}
+
+func (compiler ValidatingCompiler[Endpoint]) RootWithMethodNotAllowed(
+	missing Endpoint,
+	methodNotAllowed Endpoint,
+) func(branches ...[]RadixRoute[Endpoint]) []RouteConflict {
+	return func(branches ...[]RadixRoute[Endpoint]) []RouteConflict {
+		return validateRoutes(flatten(branches))
+	}
+}
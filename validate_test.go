@@ -0,0 +1,194 @@
+package http_routing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidatingCompiler(t *testing.T) {
+	dsl := NewValidatingCompiler[string]()
+	var tests = []struct {
+		name     string
+		result   []RouteConflict
+		expected []RouteConflict
+	}{
+		{
+			name: "no conflicts",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(
+					dsl.Post("ApiCreateUser"),
+					dsl.Path("/me")(dsl.Get("ApiFetchSelf")),
+					dsl.Param("user_id")(
+						dsl.Get("ApiFetchUser"),
+						dsl.Delete("ApiDeleteUser"),
+					),
+				),
+				dsl.Path("/static")(dsl.CatchAll("file")(dsl.Get("StaticFile"))),
+			),
+			expected: []RouteConflict{},
+		},
+		{
+			name: "any before an explicit catch-all",
+			result: dsl.Root("Missing")(
+				dsl.Path("/health")(dsl.Any("HealthAny")),
+				dsl.Path("/ping")(dsl.Any("PingAny"), dsl.Get("Ping")),
+				dsl.CatchAll("path")(dsl.Get("StaticFile")),
+			),
+			expected: []RouteConflict{},
+		},
+		{
+			name: "explicit method shadowed by earlier any",
+			result: dsl.Root("Missing")(
+				dsl.CatchAll("path")(dsl.Any("Proxy")),
+				dsl.Path("/health")(dsl.Get("Health")),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/health", "/{path...}"},
+			},
+		},
+		{
+			name: "any partly shadowed by earlier explicit method",
+			result: dsl.Root("Missing")(
+				dsl.CatchAll("path")(dsl.Get("StaticFile")),
+				dsl.Path("/health")(dsl.Any("HealthAny")),
+			),
+			expected: []RouteConflict{
+				{AmbiguousRoute, AnyMethod, "/health", "/{path...}"},
+			},
+		},
+		{
+			name: "duplicate route",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(dsl.Param("user_id")(dsl.Get("ApiFetchUser"))),
+				dsl.Path("/users")(dsl.Param("id")(dsl.Get("ApiFetchUserAgain"))),
+			),
+			expected: []RouteConflict{
+				{DuplicateRoute, "GET", "/users/{id}", "/users/{user_id}"},
+			},
+		},
+		{
+			name: "duplicate route across path splits",
+			result: dsl.Root("Missing")(
+				dsl.Path("/api/users")(dsl.Get("ApiListUsers")),
+				dsl.Path("/api")(dsl.Path("/users")(dsl.Get("ApiListUsersAgain"))),
+			),
+			expected: []RouteConflict{
+				{DuplicateRoute, "GET", "/api/users", "/api/users"},
+			},
+		},
+		{
+			name: "static shadowed by earlier param",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(
+					dsl.Param("user_id")(dsl.Get("ApiFetchUser")),
+					dsl.Path("/me")(dsl.Get("ApiFetchSelf"), dsl.Put("ApiUpdateSelf")),
+				),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/users/me", "/users/{user_id}"},
+			},
+		},
+		{
+			name: "constrained param shadowed by earlier param",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(
+					dsl.Param("slug")(dsl.Get("ApiFetchUserBySlug")),
+					dsl.ConstrainedParam("user_id", IntConstraint())(dsl.Get("ApiFetchUser")),
+				),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/users/{user_id:int}", "/users/{slug}"},
+			},
+		},
+		{
+			name: "static not matching earlier constrained param",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(
+					dsl.ConstrainedParam("user_id", IntConstraint())(dsl.Get("ApiFetchUser")),
+					dsl.Path("/me")(dsl.Get("ApiFetchSelf")),
+					dsl.Path("/1337")(dsl.Get("ApiFetchLeet")),
+				),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/users/1337", "/users/{user_id:int}"},
+			},
+		},
+		{
+			name: "route shadowed by earlier catch all",
+			result: dsl.Root("Missing")(
+				dsl.Path("/static")(
+					dsl.CatchAll("file")(dsl.Get("StaticFile")),
+					dsl.Path("/css")(dsl.Param("file")(dsl.Get("StaticCss"))),
+				),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/static/css/{file}", "/static/{file...}"},
+			},
+		},
+		{
+			name: "ambiguous param and static overlap",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(
+					dsl.Param("user_id")(dsl.Path("/posts")(dsl.Get("ApiFetchUserPosts"))),
+					dsl.Path("/me")(dsl.Param("collection")(dsl.Get("ApiFetchSelfCollection"))),
+				),
+			),
+			expected: []RouteConflict{
+				{AmbiguousRoute, "GET", "/users/me/{collection}", "/users/{user_id}/posts"},
+			},
+		},
+		{
+			name: "hosts",
+			result: dsl.Root("Missing")(
+				dsl.Host("api.example.com")(dsl.Path("/users")(dsl.Get("ApiListUsers"))),
+				dsl.Host("www.example.com")(dsl.Path("/users")(dsl.Get("WwwListUsers"))),
+				dsl.Path("/users")(dsl.Get("ListUsers")),
+				dsl.Host("admin.example.com")(dsl.Path("/users")(dsl.Get("AdminListUsers"))),
+				dsl.Host("api.example.com")(dsl.Path("/users")(dsl.Get("ApiListUsersAgain"))),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "admin.example.com/users", "/users"},
+				{DuplicateRoute, "GET", "api.example.com/users", "api.example.com/users"},
+			},
+		},
+		{
+			name: "predicates",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(
+					dsl.Where(AcceptPredicate("application/vnd.v2+json"))(dsl.Get("ListUsersV2")),
+					dsl.Get("ListUsers"),
+					dsl.Where(QueryPredicate("beta", "1"))(dsl.Get("ListUsersBeta")),
+				),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/users [query(beta=1)]", "/users"},
+			},
+		},
+		{
+			name: "unreachable by construction",
+			result: dsl.Root("Missing")(
+				dsl.Path("/users")(dsl.Param("user_id")(dsl.Path(".json")(dsl.Get("ApiFetchUserJson")))),
+				dsl.Path("/static")(dsl.CatchAll("file")(dsl.Path("/index.html")(dsl.Get("StaticIndex")))),
+			),
+			expected: []RouteConflict{
+				{UnreachableRoute, "GET", "/users/{user_id}.json", ""},
+				{UnreachableRoute, "GET", "/static/{file...}/index.html", ""},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !reflect.DeepEqual(test.result, test.expected) {
+				t.Errorf("got %+v, want %+v", test.result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRouteConflictString(t *testing.T) {
+	conflict := RouteConflict{UnreachableRoute, "GET", "/users/me", "/users/{user_id}"}
+	expected := "unreachable route GET /users/me conflicts with GET /users/{user_id}"
+	if result := conflict.String(); result != expected {
+		t.Errorf("got %q, want %q", result, expected)
+	}
+}